@@ -5,118 +5,197 @@ import (
 	"flag"
 	"fmt"
 	"go/scanner"
-	"log"
-	"os"
-	"os/signal"
+	"io"
 	"runtime/debug"
-	"syscall"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
+)
 
-	"github.com/eugenenosenko/gopoly/config"
-	"github.com/eugenenosenko/gopoly/generator"
-	"github.com/eugenenosenko/gopoly/internal/xfs"
-	"github.com/eugenenosenko/gopoly/poly"
-	"github.com/eugenenosenko/gopoly/source"
+// Exit codes of the commands. Meaning of ExitProblems depends on the command, see command help.
+const (
+	ExitOK       = 0
+	ExitProblems = 1
+	ExitFailure  = 2
+	ExitPanic    = 3
 )
 
-type RecoveryFunc func(*App)
+// errProblems is returned by the commands that reported the problems they were looking for,
+// i.e. stale generated files or lint diagnostics.
+var errProblems = errors.New("problems reported")
+
+// usageError is returned when command is invoked with invalid arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
 
+// App runs gopoly commands. Commands write their results to Stdout, while logs, errors and
+// diagnostics go to Stderr, so that App can be run in-process, i.e. in tests.
 type App struct {
-	Logf           func(format string, args ...any)
-	Ctx            context.Context
-	RecoveryFunc   RecoveryFunc
-	ConfigProvider func() (*config.Config, error)
+	Logf   func(format string, args ...any)
+	Stdout io.Writer
+	Stderr io.Writer
+	Info   *RunInfo
 }
 
-func (a *App) Exit(status int) {
-	os.Exit(status)
+// command is a gopoly subcommand with its own flag set.
+type command struct {
+	Name string
+	// Args is the synopsis of the flags and arguments of the command.
+	Args string
+	// Short is a one-line description shown in the list of commands.
+	Short string
+	// Long is the description shown in the command help.
+	Long string
+	// Problems describes what is reported with ExitProblems, empty when command doesn't report problems.
+	Problems string
+	// Flags defines the flags of the command on the flag.FlagSet and returns function that runs the command
+	// with the remaining arguments once flags are parsed.
+	Flags func(a *App, fs *flag.FlagSet) func(ctx context.Context, args []string) error
 }
 
-func (a *App) Execute(info *RunInfo) {
-	defer a.RecoveryFunc(a)
-	if len(os.Args) > 2 && isInitCmd(os.Args[1]) {
-		var code int
-		if err := a.RunInit(); err != nil {
-			log.Printf("Failed to execute init command %v", err)
-			code = 3
+// defaultCommand is run when arguments don't start with a command name, i.e. gopoly -c .gopoly.yaml
+// or gopoly ./...
+const defaultCommand = "generate"
+
+var commands = []*command{
+	generateCommand(),
+	initCommand(),
+	validateCommand(),
+	checkCommand(),
+	listCommand(),
+	explainCommand(),
+	lintCommand(),
+	openAPICommand(),
+	schemaCommand(),
+	versionCommand(),
+}
+
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.Name == name {
+			return c
 		}
-		a.Exit(code)
 	}
+	return nil
+}
 
-	a.Logf("Run info: %s", info)
-
-	sig := make(chan os.Signal, 1)
-	ctx, cancel := context.WithCancel(a.Ctx)
-	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
-
+// Run runs the command selected by args, i.e. os.Args[1:], and returns its exit code.
+// Panics are recovered and reported with ExitPanic.
+func (a *App) Run(ctx context.Context, args []string) (code int) {
 	defer func() {
-		signal.Stop(sig)
-		cancel()
-	}()
-
-	// trap cancellation signal on the context
-	go func() {
-		select {
-		case <-sig:
-			cancel()
-		case <-ctx.Done():
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(a.Stderr, "panic occurred; %v, stack %s", r, debug.Stack())
+			code = ExitPanic
 		}
 	}()
-	a.execute()
-}
 
-func isInitCmd(arg string) bool {
-	return arg == "init"
-}
+	name := defaultCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") && !isPattern(args[0]) {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		return a.help(args)
+	}
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		_, _ = fmt.Fprintf(a.Stderr, "gopoly: unknown command %q\n", name)
+		a.usage()
+		return ExitFailure
+	}
 
-func (a *App) execute() {
-	s, err := source.NewLoader(&source.Config{Logf: log.Printf, LoadFunc: source.LoadFromPackage})
-	if err != nil {
-		reportAndExit(a, errors.Wrapf(err, "creating source.Loader"))
+	fs := a.flagSet(cmd)
+	run := cmd.Flags(a, fs)
+	if err := fs.Parse(args); err != nil {
+		// flag.FlagSet reports the error and usage itself
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitFailure
 	}
 
-	gen, err := generator.NewTemplateGenerator(&generator.Config{
-		Logf:     log.Printf,
-		Provider: xfs.FileWriterProviderFunc(os.Create),
-	})
-	if err != nil {
-		reportAndExit(a, errors.Wrapf(err, "creating codegen.Generator"))
+	err := run(ctx, fs.Args())
+	var uerr usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, errProblems):
+		return ExitProblems
+	case errors.As(err, &uerr):
+		_, _ = fmt.Fprintf(a.Stderr, "%s\n", uerr)
+		fs.Usage()
+		return ExitFailure
 	}
+	scanner.PrintError(a.Stderr, err)
+	return ExitFailure
+}
+
+// isPattern reports whether the argument is a package pattern rather than a command name, i.e. ./...
+func isPattern(arg string) bool {
+	return strings.HasPrefix(arg, ".") || strings.Contains(arg, "/")
+}
 
-	runner, err := poly.NewClient(&poly.Config{
-		Logf:          log.Printf,
-		SourceLoader:  s,
-		CodeGenerator: gen,
-	})
-	if err != nil {
-		reportAndExit(a, errors.Wrapf(err, "creating poly.Client"))
+func (a *App) flagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	fs.Usage = func() {
+		a.commandUsage(cmd, fs)
 	}
+	return fs
+}
 
-	conf, err := a.ConfigProvider()
-	if err != nil {
-		reportAndExit(a, errors.Wrapf(err, "getting types configuration"))
+// help prints usage of the command provided in args or the list of commands.
+func (a *App) help(args []string) int {
+	if len(args) == 0 {
+		a.usage()
+		return ExitOK
 	}
-	if err = runner.Run(a.Ctx, conf); err != nil {
-		reportAndExit(a, err)
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		_, _ = fmt.Fprintf(a.Stderr, "gopoly: unknown command %q\n", args[0])
+		a.usage()
+		return ExitFailure
 	}
-	os.Exit(0)
+	fs := a.flagSet(cmd)
+	cmd.Flags(a, fs)
+	fs.Usage()
+	return ExitOK
 }
 
-func usage() {
-	_, _ = fmt.Fprintf(os.Stderr, "usage: gopoly [-p package-path] [-c config-file] [-d decoding-strategy]"+
-		" [-o output-file] [-m marker-method] [-t type-info]\n")
-	flag.PrintDefaults()
+func (a *App) usage() {
+	_, _ = fmt.Fprintf(a.Stderr, "usage: gopoly <command> [flags] [arguments]\n\nCommands:\n")
+	w := tabwriter.NewWriter(a.Stderr, 0, 4, 2, ' ', 0)
+	for _, c := range commands {
+		short := c.Short
+		if c.Name == defaultCommand {
+			short += " (default)"
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", c.Name, short)
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintf(a.Stderr, "\nRun 'gopoly help <command>' for flags and exit codes of the command.\n")
 }
 
-func PrintTraceAndExit(app *App) {
-	if r := recover(); r != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "panic occurred; %v, stack %s", r, debug.Stack())
+func (a *App) commandUsage(cmd *command, fs *flag.FlagSet) {
+	_, _ = fmt.Fprintf(a.Stderr, "usage: gopoly %s %s\n\n%s\n", cmd.Name, cmd.Args, cmd.Long)
+
+	var hasFlags bool
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		_, _ = fmt.Fprintf(a.Stderr, "\nFlags:\n")
+		fs.PrintDefaults()
 	}
-	app.Exit(3)
-}
 
-func reportAndExit(app *App, err error) {
-	scanner.PrintError(os.Stderr, err)
-	app.Exit(2)
+	_, _ = fmt.Fprintf(a.Stderr, "\nExit codes:\n")
+	w := tabwriter.NewWriter(a.Stderr, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "  %d\tsuccess\n", ExitOK)
+	if cmd.Problems != "" {
+		_, _ = fmt.Fprintf(w, "  %d\t%s\n", ExitProblems, cmd.Problems)
+	}
+	_, _ = fmt.Fprintf(w, "  %d\tinvalid arguments or configuration, or the command failed\n", ExitFailure)
+	_, _ = fmt.Fprintf(w, "  %d\tinternal error\n", ExitPanic)
+	_ = w.Flush()
 }
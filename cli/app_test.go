@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func run(args ...string) (code int, stdout, stderr string) {
+	var out, errs bytes.Buffer
+	app := &App{
+		Logf:   func(string, ...any) {},
+		Stdout: &out,
+		Stderr: &errs,
+		Info:   &RunInfo{ModVersion: "v1.2.3", ModSum: "h1:sum"},
+	}
+	code = app.Run(context.Background(), args)
+	return code, out.String(), errs.String()
+}
+
+func TestApp_Run(t *testing.T) {
+	t.Run("should print version", func(t *testing.T) {
+		code, stdout, _ := run("version")
+		require.Equal(t, ExitOK, code)
+		require.Contains(t, stdout, "gopoly v1.2.3")
+		require.Contains(t, stdout, "mod-sum: h1:sum")
+	})
+
+	t.Run("should print usage of the command with exit codes", func(t *testing.T) {
+		code, _, stderr := run("help", "check")
+		require.Equal(t, ExitOK, code)
+		require.Contains(t, stderr, "usage: gopoly check")
+		require.Contains(t, stderr, "-c file")
+		require.Contains(t, stderr, "1  generated files are missing or out-of-date")
+
+		code, _, stderr = run("validate", "-h")
+		require.Equal(t, ExitOK, code)
+		require.Contains(t, stderr, "usage: gopoly validate")
+	})
+
+	t.Run("should fail on unknown command", func(t *testing.T) {
+		code, _, stderr := run("unknown")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, `unknown command "unknown"`)
+		require.Contains(t, stderr, "generate  generate decoders of the configured interfaces (default)")
+	})
+
+	t.Run("should fail on invalid flags and arguments", func(t *testing.T) {
+		code, _, stderr := run("-x")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "flag provided but not defined: -x")
+
+		code, _, stderr = run("list", "-c", "testdata/valid.yaml", "extra")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "list takes no arguments")
+	})
+
+	t.Run("should validate config", func(t *testing.T) {
+		code, _, _ := run("validate", "-c", "testdata/valid.yaml")
+		require.Equal(t, ExitOK, code)
+
+		code, _, stderr := run("validate", "-c", "testdata/invalid.yaml")
+		require.Equal(t, ExitProblems, code)
+		require.Equal(t, `testdata/invalid.yaml:3:24: type Event: can't have discriminator decoding and empty mapping
+testdata/invalid.yaml:8:24: type Shape: can't have discriminator mapping & strict decoding
+testdata/invalid.yaml:13:21: type Shape: discriminator mapping value Triangle is not a variant
+testdata/invalid.yaml:14:5: unknown key filename in type Shape
+testdata/invalid.yaml:15:11: duplicate type Event, first defined at line 2
+testdata/invalid.yaml:16:24: type Event: not a valid decoding-strategy dynamic
+`, stderr)
+
+		code, _, _ = run("validate", "-c", "testdata/valid.yaml", "-d", "unknown")
+		require.Equal(t, ExitProblems, code)
+	})
+
+	t.Run("should list configured types", func(t *testing.T) {
+		code, stdout, _ := run("list", "-c", "testdata/valid.yaml", "-t", "Animal subtypes=Cat,Dog")
+		require.Equal(t, ExitOK, code)
+		require.Equal(t, `NAME    PACKAGE                             STRATEGY       VARIANTS       OUTPUT
+Animal  github.com/username/example/models  strict         Cat,Dog        gopoly.gen.go
+Event   github.com/username/example/models  discriminator  -              event.gen.go
+Shape   github.com/username/example/models  strict         Square,Circle  gopoly.gen.go
+`, stdout)
+	})
+
+	t.Run("should resolve package path relative to the working directory", func(t *testing.T) {
+		code, stdout, _ := run("list", "-c", "testdata/valid.yaml", "-p", "./testdata/models")
+		require.Equal(t, ExitOK, code)
+		require.Contains(t, stdout, "Shape  github.com/eugenenosenko/gopoly/cli/testdata/models")
+	})
+
+	t.Run("should merge repeated config files", func(t *testing.T) {
+		code, stdout, _ := run("list", "-c", "testdata/valid.yaml", "-c", "testdata/override.yaml")
+		require.Equal(t, ExitOK, code)
+		require.Equal(t, `NAME    PACKAGE                              STRATEGY       VARIANTS                OUTPUT
+Animal  github.com/username/example/animals  strict         Cat,Dog                 shapes.gen.go
+Event   github.com/username/example/models   discriminator  -                       event.gen.go
+Shape   github.com/username/example/models   strict         Square,Circle,Triangle  shapes.gen.go
+`, stdout)
+	})
+
+	t.Run("should fail when named config file doesn't exist", func(t *testing.T) {
+		code, _, stderr := run("list", "-c", "testdata/valid.yaml", "-c", "testdata/missing.yaml")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "config file testdata/missing.yaml doesn't exist")
+	})
+
+	t.Run("should generate config files of the packages at once", func(t *testing.T) {
+		// packages are generated in a copy, so that the checked-in testdata stays untouched
+		dir := t.TempDir()
+		copyDir(t, filepath.Join("testdata", "packages"), filepath.Join(dir, "packages"))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, "go.mod"), []byte("module github.com/eugenenosenko/gopoly/cli/testdata\n\ngo 1.19\n"), 0o644))
+		chdir(t, dir)
+
+		code, stdout, _ := run("check", "./packages/...")
+		require.Equal(t, ExitProblems, code)
+		require.Contains(t, stdout, filepath.Join("packages", "events", "events.gen.go"))
+		require.Contains(t, stdout, filepath.Join("packages", "shapes", "gopoly.gen.go"))
+
+		code, stdout, _ = run("./packages/...")
+		require.Equal(t, ExitOK, code)
+		require.Equal(t, `PACKAGE                                                       TYPES
+github.com/eugenenosenko/gopoly/cli/testdata/packages/events  Event
+github.com/eugenenosenko/gopoly/cli/testdata/packages/shapes  Shape
+`, stdout)
+
+		code, stdout, _ = run("check", "./packages/...")
+		require.Equal(t, ExitOK, code)
+		require.Empty(t, stdout)
+	})
+
+	t.Run("should report problems of all the config files of the packages", func(t *testing.T) {
+		code, _, stderr := run("validate", "./testdata/broken/...")
+		require.Equal(t, ExitProblems, code)
+		require.Equal(t, `testdata/broken/events/.gopoly.yaml:3:24: type Event: can't have discriminator decoding and empty mapping
+testdata/broken/shapes/.gopoly.yaml:3:5: unknown key variant in type Shape
+`, stderr)
+
+		code, _, stderr = run("generate", "-c", "testdata/valid.yaml", "./testdata/packages/...")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "-c, -p and -t can't be used with package patterns")
+	})
+
+	t.Run("should create config from the scanned package and not overwrite it unless forced", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), ".gopoly.yaml")
+
+		code, _, _ := run("init", "-c", filename, "../scan/testdata/models")
+		require.Equal(t, ExitOK, code)
+		c, err := config.NewFromYAML(filename)
+		require.NoError(t, err)
+		require.Equal(t, "github.com/eugenenosenko/gopoly/scan/testdata/models", c.Package)
+		require.Equal(t, config.TypesList{
+			{
+				Name:             "Event",
+				Variants:         []string{"CreatedEvent", "DeletedEvent"},
+				DecodingStrategy: config.DecodingStrategyDiscriminator,
+				Discriminator: config.DiscriminatorDefinition{
+					Field:   "type",
+					Mapping: map[string]string{"CreatedEvent": "CreatedEvent", "DeletedEvent": "DeletedEvent"},
+				},
+			},
+			{
+				Name:             "Shape",
+				Variants:         []string{"Circle", "Square"},
+				MarkerMethod:     "shape",
+				DecodingStrategy: config.DecodingStrategyStrict,
+			},
+		}, c.Types)
+
+		code, _, stderr := run("init", "-c", filename, "../scan/testdata/models")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "already exists, use -f to overwrite it")
+
+		chdir(t, "../scan/testdata/models")
+		code, _, _ = run("init", "-c", filename, "-f")
+		require.Equal(t, ExitOK, code)
+		c, err = config.NewFromYAML(filename)
+		require.NoError(t, err)
+		require.Equal(t, "github.com/eugenenosenko/gopoly/scan/testdata/models", c.Package, "working directory is scanned")
+	})
+
+	t.Run("should explain resolved interfaces", func(t *testing.T) {
+		code, stdout, _ := run("explain", "-c", "testdata/explain.yaml", "Event")
+		require.Equal(t, ExitOK, code)
+		require.Equal(t, `Event
+  package:            github.com/eugenenosenko/gopoly/cli/testdata/models
+  marker method:      IsEvent
+  decoding strategy:  discriminator
+  output:             event.gen.go
+  discriminator:      type
+    "CLEARED"         => ClearedEvent
+    "DRAWN"           => DrawnEvent
+  variants:
+    ClearedEvent (value receiver)
+      no polymorphic fields
+    DrawnEvent (value receiver)
+      Shape   scalar  github.com/eugenenosenko/gopoly/cli/testdata/models.Shape  prefix -  `+"`json:\"shape\"`"+`
+      Shapes  slice   github.com/eugenenosenko/gopoly/cli/testdata/models.Shape  prefix -  `+"`json:\"shapes\"`"+`
+`, stdout)
+
+		code, stdout, _ = run("explain", "-c", "testdata/explain.yaml", "Shape")
+		require.Equal(t, ExitOK, code)
+		require.Contains(t, stdout, "marker method:      IsShape")
+		require.Contains(t, stdout, "decoding strategy:  strict")
+		require.Contains(t, stdout, "Square (pointer receiver)")
+
+		code, _, stderr := run("explain", "-c", "testdata/explain.yaml", "Unknown")
+		require.Equal(t, ExitFailure, code)
+		require.Contains(t, stderr, "type Unknown is not configured")
+	})
+}
+
+// copyDir copies files of the src directory tree to dst.
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	require.NoError(t, err)
+}
+
+// chdir changes the working directory for the duration of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+}
@@ -1,111 +1,129 @@
 package cli
 
 import (
-	"bytes"
-	"flag"
-	"fmt"
-	"os"
-	"regexp"
-	"text/template"
+	"go/token"
+	"strings"
 
 	"github.com/pkg/errors"
-	"golang.org/x/exp/maps"
-	"gopkg.in/yaml.v3"
 
 	"github.com/eugenenosenko/gopoly/config"
-	"github.com/eugenenosenko/gopoly/internal/xfs"
+	"github.com/eugenenosenko/gopoly/gqlgen"
+	"github.com/eugenenosenko/gopoly/openapi"
 )
 
-func MergedConfigProvider() (*config.Config, error) {
-	flag.Parse()
-	flag.Usage = usage
-	return newMergedConfig()
+// configReader reads the config files importing types from OpenAPI documents and gqlgen projects.
+var configReader = config.Reader{OpenAPI: openapi.ImportTypes, GQLGen: gqlgen.ImportTypes}
+
+// configure returns the configuration of the config files found next to the packages matching the patterns
+// or, when there are no patterns, the configuration of the config files provided with the flags.
+func configure(f *configFlags, patterns []string) (*config.Config, error) {
+	if len(patterns) > 0 {
+		return newPackagesConfig(f, patterns)
+	}
+	return newMergedConfig(f)
 }
 
-func newMergedConfig() (*config.Config, error) {
-	var target *config.Config
-	if filename := *cfg; filename != "" && xfs.FileExists(filename) {
-		yamlConf, err := newConfigFromYAML(filename)
-		if err != nil {
-			return nil, err
-		}
-		target = yamlConf
-	} else {
-		target = defaultConfig
+// newMergedConfig reads and merges the config files and overwrites their inputs with the flags set on
+// the command line. Default configuration is used when there is no config file.
+func newMergedConfig(f *configFlags) (*config.Config, error) {
+	files, err := f.filenames()
+	if err != nil {
+		return nil, err
 	}
-	if filename := *out; filename != "" {
-		target.Output.Filename = filename
+	target, err := configReader.Load(files...)
+	if err != nil {
+		return nil, err
 	}
-	if m := *method; m != "" {
-		target.MarkerMethod = m
+	// overwrite what is defined in config files with input from CLI, keeping the order of the types
+	target.Types = config.OverrideTypes(target.Package, target.Types, f.types.types)
+	if err = f.apply(target); err != nil {
+		if len(files) > 0 {
+			return nil, errors.Wrapf(err, "normalizing config %s", strings.Join(files, ", "))
+		}
+		return nil, err
 	}
-	if p := *pack; p != "" {
-		target.Package = p
+	return target, nil
+}
+
+// newPackagesConfig combines the config files found next to the packages matching the patterns, i.e. ./...,
+// into the configuration of all their types. Types of the config file without package belong to the package
+// of its directory. Problems of all the config files are reported together.
+func newPackagesConfig(f *configFlags, patterns []string) (*config.Config, error) {
+	if f.isSet("c") || f.isSet("p") || f.isSet("t") {
+		return nil, usageError("-c, -p and -t can't be used with package patterns")
 	}
-	if s := *strategy; s != "" {
-		target.DecodingStrategy = config.DecodingStrategy(s)
+	files, err := config.Discover(defaultConfigFile, patterns...)
+	if err != nil {
+		return nil, err
 	}
-
-	ntype := target.Types.AssociateByTypeName()
-	// overwrite what is defined in config file with input from CLI
-	for _, t := range types.types {
-		ntype[t.Name] = t
+	if len(files) == 0 {
+		return nil, errors.Errorf("no %s files found in %s", defaultConfigFile, strings.Join(patterns, " "))
 	}
 
-	for _, t := range ntype {
-		if t.Package == "" {
-			t.Package = target.Package
-		}
-		if t.MarkerMethod == "" {
-			t.MarkerMethod = target.MarkerMethod
-		}
-		if t.Output == nil || t.Output.Filename == "" {
-			t.Output = target.Output
-		}
-		// validate decoding strategy inputs
-		if t.DecodingStrategy == config.DecodingStrategyStrict && len(t.Discriminator.Mapping) > 0 {
-			return nil, errors.New("can't have discriminator mapping & strict decoding")
+	var problems config.Problems
+	report := func(filename string, err error) {
+		var pp config.Problems
+		if errors.As(err, &pp) {
+			problems = append(problems, pp...)
+		} else {
+			problems = append(problems, &config.Problem{Pos: token.Position{Filename: filename}, Message: err.Error()})
 		}
-		if t.DecodingStrategy == config.DecodingStrategyDiscriminator && len(t.Discriminator.Mapping) == 0 {
-			return nil, errors.New("can't have discriminator decoding and empty mapping")
+	}
+
+	target := &config.Config{Types: config.TypesList{}}
+	configured := make(map[string]string, 0)
+	for _, file := range files {
+		c, err := configReader.NewFromYAML(file.Filename)
+		if err != nil {
+			report(file.Filename, err)
+			continue
 		}
-		if t.DecodingStrategy == "" && len(t.Discriminator.Mapping) > 0 {
-			t.DecodingStrategy = config.DecodingStrategyDiscriminator
-		} else if t.DecodingStrategy == "" {
-			t.DecodingStrategy = target.DecodingStrategy
+		if c.Package == "" {
+			c.Package = file.Package
 		}
-		if ds := t.DecodingStrategy; !ds.IsValid() {
-			return nil, fmt.Errorf("not a valid decoding-strategy %s", ds)
+		if err = f.apply(c); err != nil {
+			report(file.Filename, err)
+			continue
 		}
-	}
-	target.Types = maps.Values(ntype)
-
-	rx := regexp.MustCompile(`{{\s?\.(\w+)\s?}}`)
-	for _, def := range target.Types {
-		if m := def.MarkerMethod; rx.MatchString(m) {
-			parse, err := template.New("").Parse(m)
-			if err != nil {
-				return nil, errors.Wrap(err, "parsing marker-method template field")
-			}
-			var b bytes.Buffer
-			if err = parse.Execute(&b, map[string]string{"Name": def.Name}); err != nil {
-				return nil, errors.Wrap(err, "executing marker-method template")
+		for _, t := range c.Types {
+			key := t.Package + "." + t.Name
+			if other, ok := configured[key]; ok {
+				report(file.Filename, errors.Errorf("type %s is already configured by %s", key, other))
+				continue
 			}
-			def.MarkerMethod = b.String()
+			configured[key] = file.Filename
+			target.Types = append(target.Types, t)
 		}
 	}
-
+	if len(problems) > 0 {
+		return nil, problems
+	}
 	return target, nil
 }
 
-func newConfigFromYAML(filename string) (*config.Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, errors.Wrapf(err, "config.NewFromYAML: reading filename %s", filename)
+// apply overwrites the configuration with the flags set on the command line, sets the defaults and
+// normalizes it.
+func (f *configFlags) apply(target *config.Config) error {
+	if f.isSet("o") {
+		if target.Output == nil {
+			target.Output = &config.OutputConfig{}
+		}
+		target.Output.Filename = f.out
+	}
+	if f.isSet("m") {
+		target.MarkerMethod = f.method
+	}
+	if f.isSet("p") {
+		// package path, i.e. ./models, is relative to the working directory
+		pkg, err := config.ResolvePackage(".", f.pkg)
+		if err != nil {
+			return err
+		}
+		target.Package = pkg
 	}
-	var c config.Config
-	if err = yaml.Unmarshal(data, &c); err != nil {
-		return nil, errors.Wrapf(err, "config.NewFromYAML: unmarshaling filename %s", filename)
+	if f.isSet("d") {
+		target.DecodingStrategy = config.DecodingStrategy(f.strategy)
 	}
-	return &c, nil
+	target.SetDefaults()
+	return target.Normalize()
 }
@@ -14,12 +14,32 @@ file. If both are provided, inputs from command line overwrite inputs from confi
 
 Usage:
 
-	gopoly [flags]
+	gopoly <command> [flags] [arguments]
 
-The flags are:
+The commands are:
+
+	generate  generate decoders of the configured interfaces, default when command is omitted
+	init      create .gopoly.yaml config file
+	validate  validate the configuration without loading the packages
+	check     check generated files are up-to-date without writing them
+	list      list the configured interfaces
+	explain   explain how the configured interfaces are resolved
+	lint      check the code against the configuration
+	openapi   write OpenAPI components of the configured interfaces
+	schema    write JSON schema of the config file
+	version   print the version of gopoly
+
+Use "gopoly help <command>" for flags and exit codes of the command.
+
+Commands exit with code 0 on success, 1 when they report the problems they look for, i.e. invalid
+configuration of validate, out-of-date files of check or diagnostics of lint, 2 when flags, arguments or
+configuration are invalid or command fails and 3 on internal errors.
+
+The flags of generate, validate, check, list and explain are:
 
 	-c
-		Provide path to the config file. Default value is .gopoly.yaml
+		Provide path to the config file, can be repeated to merge several files. Default value is
+		.gopoly.yaml found in the current directory or its parents up to the module root.
 	-p
 		Scoped package where models are located.
 	-d
@@ -40,23 +60,55 @@ The flags are:
 
 Generate unmarshaling functions based on the default config file:
 
-	gopoly
+	gopoly generate
 
 Generate unmarshaling functions based on the custom named config file:
 
-	gopoly -c .gopoly-config.yaml
+	gopoly generate -c .gopoly-config.yaml
+
+Generate unmarshaling functions based on the .gopoly.yaml files next to the packages of the module:
+
+	gopoly ./...
 
 Generate unmarshaling based on command input only:
 
-	gopoly -p "github.com/username/example/models" \
+	gopoly generate -p "github.com/username/example/models" \
 		-o "out.gen.go" \
 		-d "strict" \
 		-m "IsRunner" \
 		-t 'Runner subtypes=A,B'
 
+Create configuration from the interfaces of the packages:
+
+	gopoly init ./models/...
+
+Create configuration from the oneOf schemas of the OpenAPI document:
+
+	gopoly init -openapi api.yaml -p "github.com/username/example/api"
+
+Create configuration from the unions and interfaces of the gqlgen project:
+
+	gopoly init -gqlgen gqlgen.yml
+
+Check the code in the module against the configuration:
+
+	gopoly lint -c .gopoly.yaml ./...
+
+Check generated files are up-to-date, i.e. in CI:
+
+	gopoly check -c .gopoly.yaml
+
+Explain how the interface is resolved, i.e. its variants and their polymorphic fields:
+
+	gopoly explain -c .gopoly.yaml UserEvent
+
+Write OpenAPI components of the configured interfaces:
+
+	gopoly openapi -c .gopoly.yaml -o api/components.json
+
 Generate unmarshaling based on custom config file and command input :
 
-	gopoly -c .gopoly-config.yaml \
+	gopoly generate -c .gopoly-config.yaml \
 		-p "github.com/username/example/models" \
 		-o "out.gen.go" \
 		-d "strict" \
@@ -4,33 +4,89 @@ import (
 	"flag"
 	"strings"
 
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 
 	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/internal/xfs"
 )
 
-var (
-	cfg      = flag.String("c", ".gopoly.yaml", "config file that contains gopoly configuration")
-	pack     = flag.String("p", "", "scoped package path where models are located")
-	strategy = flag.String("d", "strict", "decoding strategy, either 'strict' or 'discriminator'")
-	out      = flag.String("o", "", "output filename that will contain generated code")
-	method   = flag.String("m", "Is{{.Name}}", "marker method or template that is used to identify polymorphic relations")
-
-	types         = &TypesInput{usage: "codegen configuration for the polymorphic types"}
-	defaultConfig = &config.Config{
-		Types:            []*config.TypeDefinition{},
-		DecodingStrategy: config.DecodingStrategyStrict,
-		MarkerMethod:     "Is{{.Name}}",
-		Package:          "",
+const defaultConfigFile = ".gopoly.yaml"
+
+// configFlags are the flags of the commands working with the configuration, they overwrite inputs
+// of the config file when set.
+type configFlags struct {
+	fs       *flag.FlagSet
+	files    configFiles
+	pkg      string
+	strategy string
+	out      string
+	method   string
+	types    *TypesInput
+}
+
+// bindConfigFlags defines config file flag on fs along with the configuration flags when overrides is true.
+func bindConfigFlags(fs *flag.FlagSet, overrides bool) *configFlags {
+	f := &configFlags{fs: fs, types: &TypesInput{}}
+	fs.Var(&f.files, "c", "config `file` that contains gopoly configuration, can be repeated to merge several files "+
+		"(default "+defaultConfigFile+" found in the current directory or its parents up to the module root)")
+	if !overrides {
+		return f
 	}
-)
+	fs.StringVar(&f.pkg, "p", "", "scoped package path where models are located")
+	fs.StringVar(&f.strategy, "d", "", "decoding strategy, either 'strict' or 'discriminator' (default \"strict\")")
+	fs.StringVar(&f.out, "o", "", "output filename that will contain generated code (default \""+config.DefaultOutputFile+"\")")
+	fs.StringVar(&f.method, "m", "", "marker method or template that is used to identify polymorphic relations "+
+		"(default \""+config.DefaultMarkerMethod+"\")")
+	fs.Var(f.types, "t", "codegen configuration for the polymorphic types")
+	return f
+}
+
+// isSet reports whether flag has been provided on the command line.
+func (f *configFlags) isSet(name string) bool {
+	var set bool
+	f.fs.Visit(func(fl *flag.Flag) {
+		set = set || fl.Name == name
+	})
+	return set
+}
+
+// filenames returns the config files provided on the command line, all of which must exist, or the default
+// config file found by config.Find. Returns no files when there is no default config file.
+func (f *configFlags) filenames() ([]string, error) {
+	for _, filename := range f.files {
+		if !xfs.FileExists(filename) {
+			return nil, errors.Errorf("config file %s doesn't exist", filename)
+		}
+	}
+	if len(f.files) > 0 {
+		return f.files, nil
+	}
+	filename, err := config.Find(".", defaultConfigFile)
+	if err != nil || filename == "" {
+		return nil, err
+	}
+	return []string{filename}, nil
+}
+
+// configFiles collects the config files of the repeated flag.
+type configFiles []string
+
+func (c *configFiles) Set(s string) error {
+	*c = append(*c, s)
+	return nil
+}
 
-func init() {
-	flag.Var(types, "t", types.usage)
+func (c *configFiles) String() string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join(*c, ",")
 }
 
+var _ flag.Value = (*configFiles)(nil)
+
 type TypesInput struct {
-	usage string
 	types []*config.TypeDefinition
 }
 
@@ -56,7 +112,7 @@ func (t *TypesInput) set(typ string, details []string) error {
 		kv := strings.Split(detail, "=")
 		key, value := kv[0], kv[1]
 		switch key {
-		case "subtypes":
+		case "variants", "subtypes":
 			genDef.Variants = append(genDef.Variants, strings.Split(value, ",")...)
 		case "marker_method":
 			genDef.MarkerMethod = value
@@ -71,8 +127,12 @@ func (t *TypesInput) set(typ string, details []string) error {
 			}
 		case "decoding_strategy":
 			genDef.DecodingStrategy = config.DecodingStrategy(value)
+		case "registry":
+			genDef.Registry = value == "true"
+		case "sql_column":
+			genDef.SQLColumn = value == "true"
 		case "filename":
-			genDef.Output.Filename = value
+			genDef.Output = &config.OutputConfig{Filename: value}
 		}
 	}
 	t.types = append(t.types, genDef)
@@ -80,6 +140,9 @@ func (t *TypesInput) set(typ string, details []string) error {
 }
 
 func (t *TypesInput) String() string {
+	if t == nil || len(t.types) == 0 {
+		return ""
+	}
 	data, err := yaml.Marshal(t.types)
 	if err != nil {
 		return "main.TypesInput.String: failed build string representation of 'typesInput'"
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+)
+
+func checkCommand() *command {
+	return &command{
+		Name:  "check",
+		Args:  "[-c config-file] [-p package-path] [-d decoding-strategy] [-o output-file] [-m marker-method] [-t type-info] [packages]",
+		Short: "check generated files are up-to-date",
+		Long: `Check generates the code in memory and compares it with the files on disk without writing them.
+Files that are missing or out-of-date are printed to stdout, one per line. Accepts the flags and packages
+of generate.`,
+		Problems: "generated files are missing or out-of-date",
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, true)
+			return func(ctx context.Context, args []string) error {
+				c, err := configure(f, args)
+				if err != nil {
+					return errors.Wrap(err, "getting types configuration")
+				}
+				files := memoryFiles{}
+				if err = a.generate(ctx, c, files); err != nil {
+					return err
+				}
+
+				names := maps.Keys(files)
+				sort.Strings(names)
+				var stale int
+				for _, name := range names {
+					data, err := os.ReadFile(name)
+					if err != nil && !os.IsNotExist(err) {
+						return errors.Wrapf(err, "reading %s", name)
+					}
+					if err == nil && bytes.Equal(data, files[name].Bytes()) {
+						continue
+					}
+					_, _ = fmt.Fprintln(a.Stdout, name)
+					stale++
+				}
+				if stale > 0 {
+					a.Logf("%d generated files are out-of-date, run gopoly generate", stale)
+					return errProblems
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// memoryFiles collects generated files in memory by their names.
+type memoryFiles map[string]*bytes.Buffer
+
+func (m memoryFiles) Provide(name string) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	m[name] = buf
+	return nopCloser{buf}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
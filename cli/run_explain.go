@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/poly"
+	"github.com/eugenenosenko/gopoly/source"
+)
+
+func explainCommand() *command {
+	return &command{
+		Name:  "explain",
+		Args:  "[-c config-file] [-p package-path] [-d decoding-strategy] [-o output-file] [-m marker-method] [-t type-info] [types]",
+		Short: "explain how the configured interfaces are resolved",
+		Long: `Explain loads the packages of the configured interfaces, all of them unless types are provided, and prints
+their resolved package, marker method, decoding strategy, discriminator mapping and variants along with
+the polymorphic fields found in each variant. Accepts the flags of generate.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, true)
+			return func(ctx context.Context, args []string) error {
+				c, err := newMergedConfig(f)
+				if err != nil {
+					return errors.Wrap(err, "getting types configuration")
+				}
+				selected := make(map[string]struct{}, len(args))
+				ntype := c.Types.AssociateByTypeName()
+				for _, name := range args {
+					if _, ok := ntype[name]; !ok {
+						return usageError(fmt.Sprintf("type %s is not configured", name))
+					}
+					selected[name] = struct{}{}
+				}
+
+				loader, err := source.NewLoader(&source.Config{Logf: a.Logf, LoadFunc: source.LoadFromPackage})
+				if err != nil {
+					return errors.Wrap(err, "creating source.Loader")
+				}
+				client, err := poly.NewClient(&poly.Config{Logf: a.Logf, SourceLoader: loader})
+				if err != nil {
+					return errors.Wrap(err, "creating poly.Client")
+				}
+				ifaces, err := client.Interfaces(ctx, c)
+				if err != nil {
+					return err
+				}
+				// all the types are loaded to resolve polymorphic fields, interfaces are in the order of the types
+				var n int
+				for i, iface := range ifaces {
+					if _, ok := selected[iface.Name]; len(selected) > 0 && !ok {
+						continue
+					}
+					if n > 0 {
+						_, _ = fmt.Fprintln(a.Stdout)
+					}
+					if err = explain(a.Stdout, c.Types[i], iface); err != nil {
+						return err
+					}
+					n++
+				}
+				return nil
+			}
+		},
+	}
+}
+
+func explain(out io.Writer, def *config.TypeDefinition, iface *code.Interface) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\n", iface.Name)
+	_, _ = fmt.Fprintf(w, "  package:\t%s\n", iface.Pkg)
+	_, _ = fmt.Fprintf(w, "  marker method:\t%s\n", iface.MarkerMethod)
+	_, _ = fmt.Fprintf(w, "  decoding strategy:\t%s\n", def.DecodingStrategy)
+	if def.Output != nil && def.Output.Filename != "" {
+		_, _ = fmt.Fprintf(w, "  output:\t%s\n", def.Output.Filename)
+	}
+	if d := iface.Discriminator; d != nil {
+		_, _ = fmt.Fprintf(w, "  discriminator:\t%s\n", d.Field)
+		tags := maps.Keys(d.Mapping)
+		sort.Strings(tags)
+		for _, tag := range tags {
+			_, _ = fmt.Fprintf(w, "    %q\t=> %s\n", tag, d.Mapping[tag])
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	variants := append(code.VariantList{}, iface.Variants...)
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].Name < variants[j].Name
+	})
+	_, _ = fmt.Fprintf(out, "  variants:\n")
+	for _, v := range variants {
+		receiver := "value receiver"
+		if v.PointerReceiver {
+			receiver = "pointer receiver"
+		}
+		_, _ = fmt.Fprintf(out, "    %s (%s)\n", v.Name, receiver)
+		if len(v.Fields) == 0 {
+			_, _ = fmt.Fprintf(out, "      no polymorphic fields\n")
+			continue
+		}
+		w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, field := range v.Fields {
+			prefix := field.Prefix
+			if prefix == "" {
+				prefix = "-"
+			}
+			_, _ = fmt.Fprintf(w, "      %s\t%s\t%s.%s\tprefix %s\t%s\n",
+				field.Name, kindName(field.Kind), field.Interface.Pkg, field.Interface.Name, prefix, field.Tags)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func kindName(kind int) string {
+	switch kind {
+	case code.KindMap:
+		return "map"
+	case code.KindSlice:
+		return "slice"
+	default:
+		return "scalar"
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/generator"
+	"github.com/eugenenosenko/gopoly/internal/xfs"
+	"github.com/eugenenosenko/gopoly/poly"
+	"github.com/eugenenosenko/gopoly/source"
+)
+
+func generateCommand() *command {
+	return &command{
+		Name:  "generate",
+		Args:  "[-c config-file] [-p package-path] [-d decoding-strategy] [-o output-file] [-m marker-method] [-t type-info] [packages]",
+		Short: "generate decoders of the configured interfaces",
+		Long: `Generate loads the packages of the configured interfaces and writes the generated code next to them,
+along with the configured targets, i.e. JSON schema or TypeScript. Flags overwrite inputs of the config file.
+
+When packages are provided, i.e. gopoly ./..., the .gopoly.yaml files next to the matching packages are
+combined and generated at once, types of such config file default to the package of its directory.
+Generated packages and their types are printed to stdout.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, true)
+			return func(ctx context.Context, args []string) error {
+				if a.Info != nil {
+					a.Logf("Run info: %s", a.Info)
+				}
+				c, err := configure(f, args)
+				if err != nil {
+					return errors.Wrap(err, "getting types configuration")
+				}
+				if err = a.generate(ctx, c, xfs.FileWriterProviderFunc(xfs.CreateFile)); err != nil {
+					return err
+				}
+				if len(args) > 0 {
+					return report(a.Stdout, c)
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// report prints the packages of the generated types along with the names of the types.
+func report(out io.Writer, c *config.Config) error {
+	ptypes := c.Types.AssociateByPkgName()
+	pkgs := maps.Keys(ptypes)
+	sort.Strings(pkgs)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "PACKAGE\tTYPES\n")
+	for _, pkg := range pkgs {
+		names := make([]string, 0, len(ptypes[pkg]))
+		for _, t := range ptypes[pkg] {
+			names = append(names, t.Name)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", pkg, strings.Join(names, ","))
+	}
+	return w.Flush()
+}
+
+// generate runs poly.Client over the configuration, writing the code to the files of the provider.
+func (a *App) generate(ctx context.Context, c *config.Config, provider generator.WriterProvider) error {
+	loader, err := source.NewLoader(&source.Config{Logf: a.Logf, LoadFunc: source.LoadFromPackage})
+	if err != nil {
+		return errors.Wrap(err, "creating source.Loader")
+	}
+	gen, err := generator.NewTemplateGenerator(&generator.Config{Logf: a.Logf, Provider: provider})
+	if err != nil {
+		return errors.Wrap(err, "creating codegen.Generator")
+	}
+	client, err := poly.NewClient(&poly.Config{Logf: a.Logf, SourceLoader: loader, CodeGenerator: gen})
+	if err != nil {
+		return errors.Wrap(err, "creating poly.Client")
+	}
+	return client.Run(ctx, c)
+}
@@ -1,53 +1,151 @@
 package cli
 
 import (
+	"context"
+	"flag"
 	"os"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 
 	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/gqlgen"
+	"github.com/eugenenosenko/gopoly/openapi"
+	"github.com/eugenenosenko/gopoly/scan"
 )
 
-func (a *App) RunInit() error {
-	a.Logf("Creating example configuration YAML file .gopoly.yaml")
-	file, err := os.Create(".gopoly.yaml")
+func initCommand() *command {
+	return &command{
+		Name:  "init",
+		Args:  "[-c config-file] [-f] [-openapi openapi-file] [-p package-path] [-gqlgen gqlgen-file] [packages]",
+		Short: "create .gopoly.yaml config file",
+		Long: `Init creates .gopoly.yaml file. When packages are provided, i.e. gopoly init ./models/..., they are scanned
+for interfaces with a marker method, a method without parameters and results, implemented by the types of
+the package. Those types become the variants, and a field shared by all the variants that is commonly used as
+discriminator, i.e. type or kind, becomes the discriminator. Discriminator values are guessed to be the
+variant names and should be reviewed.
+
+Types can be imported from the OpenAPI document or the gqlgen project instead, i.e. gopoly init -openapi
+api.yaml -p github.com/username/example/api. Package of the working directory is scanned when no source is
+provided. Existing config file is not overwritten unless -f is set.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			filename := fs.String("c", defaultConfigFile, "config file to create")
+			force := fs.Bool("f", false, "overwrite existing config file")
+			spec := fs.String("openapi", "", "OpenAPI document to import types from")
+			pkg := fs.String("p", "", "package of the types generated from the OpenAPI document")
+			gql := fs.String("gqlgen", "", "gqlgen.yml of the project to import unions and interfaces from")
+			return func(ctx context.Context, args []string) error {
+				var sources int
+				for _, set := range []bool{*spec != "", *gql != "", len(args) > 0} {
+					if set {
+						sources++
+					}
+				}
+				if sources > 1 {
+					return usageError("only one of -openapi, -gqlgen and packages can be provided")
+				}
+
+				c, err := a.initConfig(*spec, *pkg, *gql, args)
+				if err != nil {
+					return err
+				}
+				return a.writeConfig(c, *filename, *force)
+			}
+		},
+	}
+}
+
+// initConfig returns configuration imported from the OpenAPI document spec, gqlgen project gql or scanned from
+// the packages matching patterns, package of the working directory is scanned when none of them is provided.
+func (a *App) initConfig(spec, pkg, gql string, patterns []string) (*config.Config, error) {
+	switch {
+	case spec != "":
+		a.Logf("Importing types from OpenAPI document %s", spec)
+		types, err := openapi.ImportTypes(spec)
+		if err != nil {
+			return nil, err
+		}
+		return &config.Config{
+			Version:          config.Version,
+			Types:            types,
+			MarkerMethod:     "Is{{ .Name }}",
+			DecodingStrategy: config.DecodingStrategyStrict,
+			Output:           &config.OutputConfig{Filename: "gopoly.gen.go"},
+			Package:          pkg,
+		}, nil
+	case gql != "":
+		a.Logf("Importing types from gqlgen project %s", gql)
+		project, err := gqlgen.Load(gql)
+		if err != nil {
+			return nil, err
+		}
+		return &config.Config{
+			Version:          config.Version,
+			Types:            gqlgen.Types(project),
+			MarkerMethod:     "Is{{ .Name }}",
+			DecodingStrategy: config.DecodingStrategyDiscriminator,
+			Output:           &config.OutputConfig{Filename: "gopoly.gen.go"},
+			Package:          project.Package,
+		}, nil
+	default:
+		if len(patterns) == 0 {
+			patterns = []string{"."}
+		}
+		a.Logf("Scanning packages %v", patterns)
+		ifaces, err := scan.Packages(patterns...)
+		if err != nil {
+			return nil, err
+		}
+		if len(ifaces) == 0 {
+			return nil, errors.Errorf("no interfaces with marker methods found in %v", patterns)
+		}
+		for _, i := range ifaces {
+			if i.Discriminator != "" {
+				a.Logf("Guessed discriminator %q of %s, review its mapping values", i.Discriminator, i.Name)
+			}
+		}
+		c := &config.Config{
+			Version:          config.Version,
+			Types:            scan.Types(ifaces),
+			MarkerMethod:     "Is{{ .Name }}",
+			DecodingStrategy: config.DecodingStrategyStrict,
+			Output:           &config.OutputConfig{Filename: "gopoly.gen.go"},
+		}
+		// types of a single package share the parent package
+		if pkgs := c.Types.AssociateByPkgName(); len(pkgs) == 1 {
+			c.Package = ifaces[0].Package
+			for _, t := range c.Types {
+				t.Package = ""
+			}
+		}
+		return c, nil
+	}
+}
+
+// writeConfig writes configuration to the YAML file, existing file is overwritten only when forced.
+func (a *App) writeConfig(c *config.Config, filename string, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	a.Logf("Creating configuration YAML file %s", filename)
+	file, err := os.OpenFile(filename, flags, 0o644)
+	if os.IsExist(err) {
+		return errors.Errorf("%s already exists, use -f to overwrite it", filename)
+	}
 	if err != nil {
-		return errors.Wrap(err, "creating .gopoly.yaml file")
+		return errors.Wrapf(err, "creating %s file", filename)
 	}
 
-	body, err := yaml.Marshal(config.Config{
-		Types: config.TypesList{
-			{
-				Name:             "A",
-				Variants:         []string{"AImpl", "BImpl"},
-				MarkerMethod:     "Is{{ .Name }}",
-				DecodingStrategy: "discriminator",
-				Package:          "github.com/username/example/events",
-				Discriminator: config.DiscriminatorDefinition{
-					Field:   "type",
-					Mapping: map[string]string{"A": "AImpl", "B": "BImpl"},
-				},
-				Output: &config.OutputConfig{
-					Filename: "a.gen.go",
-				},
-			},
-			{
-				Name: "C",
-			},
-		},
-		MarkerMethod:     "Is{{ .Name }}",
-		DecodingStrategy: "strict",
-		Output: &config.OutputConfig{
-			Filename: "gopoly.gen.go",
-		},
-		Package: "github.com/username/example/models",
-	})
+	body, err := yaml.Marshal(c)
 	if err != nil {
+		_ = file.Close()
 		return errors.Wrap(err, "marshaling config")
 	}
 
 	if _, err = file.Write(body); err != nil {
+		_ = file.Close()
 		return errors.Wrap(err, "writing config to file")
 	}
 	return file.Close()
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VetToolEnv is the environment variable set for the go vet run by lint, gopoly executable started with it
+// should run the gopoly analyzer with unitchecker.Main instead of the commands.
+const VetToolEnv = "GOPOLY_VETTOOL"
+
+func lintCommand() *command {
+	return &command{
+		Name:  "lint",
+		Args:  "[-c config-file] [packages]",
+		Short: "check the code against the configuration",
+		Long: `Lint runs go vet with the gopoly analyzer over the packages, ./... by default, and reports type switches
+that miss variants, unmapped implementations of the configured interfaces and generated files that are
+missing or stale.`,
+		Problems: "diagnostics were reported",
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, false)
+			return func(ctx context.Context, args []string) error {
+				return a.runLint(ctx, f, args)
+			}
+		},
+	}
+}
+
+// runLint runs go vet over the packages matching patterns with the gopoly executable as the vet tool,
+// marked by VetToolEnv. Returns errProblems when go vet reports diagnostics.
+func (a *App) runLint(ctx context.Context, f *configFlags, patterns []string) error {
+	files, err := f.filenames()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return usageError("config file " + defaultConfigFile + " isn't found, provide one with -c")
+	}
+	// vet tool runs in the directory of each package, so config paths have to be absolute
+	for i, filename := range files {
+		if files[i], err = filepath.Abs(filename); err != nil {
+			return errors.Wrapf(err, "resolving path of config file %s", filename)
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "resolving gopoly executable")
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	a.Logf("Linting packages %v", patterns)
+
+	args := append([]string{"vet", "-vettool=" + exe, "-gopoly.config=" + strings.Join(files, ",")}, patterns...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Env = append(os.Environ(), VetToolEnv+"=1")
+	cmd.Stdout = a.Stdout
+	cmd.Stderr = a.Stderr
+	var exitErr *exec.ExitError
+	if err = cmd.Run(); errors.As(err, &exitErr) {
+		return errProblems
+	}
+	return errors.Wrap(err, "running go vet")
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+func listCommand() *command {
+	return &command{
+		Name:  "list",
+		Args:  "[-c config-file] [-p package-path] [-d decoding-strategy] [-o output-file] [-m marker-method] [-t type-info]",
+		Short: "list the configured interfaces",
+		Long: `List prints the configured interfaces with their package, decoding strategy, variants and output file.
+Packages are not loaded, use explain to see the discovered variants. Accepts the flags of generate.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, true)
+			return func(ctx context.Context, args []string) error {
+				if len(args) > 0 {
+					return usageError("list takes no arguments")
+				}
+				c, err := newMergedConfig(f)
+				if err != nil {
+					return errors.Wrap(err, "getting types configuration")
+				}
+
+				types := c.Types
+				sort.Slice(types, func(i, j int) bool {
+					return types[i].Name < types[j].Name
+				})
+				w := tabwriter.NewWriter(a.Stdout, 0, 4, 2, ' ', 0)
+				_, _ = fmt.Fprintln(w, "NAME\tPACKAGE\tSTRATEGY\tVARIANTS\tOUTPUT")
+				for _, t := range types {
+					variants := "-"
+					if len(t.Variants) > 0 {
+						variants = strings.Join(t.Variants, ",")
+					}
+					output := "-"
+					if t.Output != nil && t.Output.Filename != "" {
+						output = t.Output.Filename
+					}
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Name, t.Package, t.DecodingStrategy, variants, output)
+				}
+				return w.Flush()
+			}
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/openapi"
+	"github.com/eugenenosenko/gopoly/poly"
+	"github.com/eugenenosenko/gopoly/source"
+)
+
+func openAPICommand() *command {
+	return &command{
+		Name:  "openapi",
+		Args:  "[-c config-file] [-o output-file] [-title title] [-version version]",
+		Short: "write OpenAPI components of the configured interfaces",
+		Long: `OpenAPI writes OpenAPI 3.1 document with components.schemas of the configured interfaces and their variants,
+i.e. gopoly openapi -c .gopoly.yaml -o api/components.json. Document is written to stdout unless the output
+file is provided.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, false)
+			output := fs.String("o", "", "output file of the OpenAPI document, stdout by default")
+			title := fs.String("title", "gopoly", "title of the OpenAPI document")
+			version := fs.String("version", "0.0.0", "version of the OpenAPI document")
+			return func(ctx context.Context, args []string) error {
+				if len(args) > 0 {
+					return usageError("openapi takes no arguments")
+				}
+				c, err := newMergedConfig(f)
+				if err != nil {
+					return errors.Wrap(err, "getting types configuration")
+				}
+				return a.runOpenAPI(ctx, c, *output, &openapi.Info{Title: *title, Version: *version})
+			}
+		},
+	}
+}
+
+// runOpenAPI writes OpenAPI document of the configured interfaces to output or stdout.
+func (a *App) runOpenAPI(ctx context.Context, c *config.Config, output string, info *openapi.Info) error {
+	loader, err := source.NewLoader(&source.Config{Logf: a.Logf, LoadFunc: source.LoadFromPackage})
+	if err != nil {
+		return errors.Wrap(err, "creating source.Loader")
+	}
+	client, err := poly.NewClient(&poly.Config{Logf: a.Logf, SourceLoader: loader})
+	if err != nil {
+		return errors.Wrap(err, "creating poly.Client")
+	}
+	ifaces, err := client.Interfaces(ctx, c)
+	if err != nil {
+		return err
+	}
+	doc, err := openapi.New(info, ifaces)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = a.Stdout
+	var f *os.File
+	if output != "" {
+		if f, err = os.Create(output); err != nil {
+			return errors.Wrapf(err, "creating %s file", output)
+		}
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(doc); err != nil {
+		if f != nil {
+			_ = f.Close()
+		}
+		return errors.Wrap(err, "writing OpenAPI document")
+	}
+	if f != nil {
+		// write errors of the file may be reported only on close
+		return errors.Wrapf(f.Close(), "closing %s file", output)
+	}
+	return nil
+}
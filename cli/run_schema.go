@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func schemaCommand() *command {
+	return &command{
+		Name:  "schema",
+		Args:  "[-o output-file]",
+		Short: "write JSON schema of the config file",
+		Long: `Schema writes JSON schema of the config file, i.e. for the editor validation of .gopoly.yaml. Schema is
+built from the configuration types, so it accepts exactly what gopoly does. Schema is written to stdout
+unless the output file is provided.`,
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			output := fs.String("o", "", "output file of the JSON schema, stdout by default")
+			return func(ctx context.Context, args []string) error {
+				if len(args) > 0 {
+					return usageError("schema takes no arguments")
+				}
+				var w io.Writer = a.Stdout
+				var f *os.File
+				if *output != "" {
+					var err error
+					if f, err = os.Create(*output); err != nil {
+						return errors.Wrapf(err, "creating %s file", *output)
+					}
+					w = f
+				}
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(config.JSONSchema()); err != nil {
+					if f != nil {
+						_ = f.Close()
+					}
+					return errors.Wrap(err, "writing JSON schema")
+				}
+				if f != nil {
+					// write errors of the file may be reported only on close
+					return errors.Wrapf(f.Close(), "closing %s file", *output)
+				}
+				return nil
+			}
+		},
+	}
+}
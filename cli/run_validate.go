@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func validateCommand() *command {
+	return &command{
+		Name:  "validate",
+		Args:  "[-c config-file] [-p package-path] [-d decoding-strategy] [-o output-file] [-m marker-method] [-t type-info] [packages]",
+		Short: "validate the configuration",
+		Long: `Validate reads the configuration and reports all the problems in it without loading the packages, i.e.
+unknown keys, duplicate types, invalid decoding strategies and discriminator mappings. Problems of the config
+file are reported as file:line:column: message. Accepts the flags and packages of generate.`,
+		Problems: "configuration is invalid",
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			f := bindConfigFlags(fs, true)
+			return func(ctx context.Context, args []string) error {
+				_, err := configure(f, args)
+				var problems config.Problems
+				switch {
+				case errors.As(err, &problems):
+					for _, p := range problems {
+						_, _ = fmt.Fprintln(a.Stderr, p)
+					}
+				case err != nil:
+					_, _ = fmt.Fprintln(a.Stderr, err)
+				default:
+					return nil
+				}
+				return errProblems
+			}
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+)
+
+func versionCommand() *command {
+	return &command{
+		Name:  "version",
+		Args:  "",
+		Short: "print the version of gopoly",
+		Long:  "Version prints the module version and checksum of gopoly along with the Go version it was built with.",
+		Flags: func(a *App, fs *flag.FlagSet) func(context.Context, []string) error {
+			return func(ctx context.Context, args []string) error {
+				if len(args) > 0 {
+					return usageError("version takes no arguments")
+				}
+				info := a.Info
+				if info == nil {
+					info = &RunInfo{}
+				}
+				version := info.ModVersion
+				if version == "" {
+					version = "(devel)"
+				}
+				_, _ = fmt.Fprintf(a.Stdout, "gopoly %s %s %s/%s\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+				if info.ModSum != "" {
+					_, _ = fmt.Fprintf(a.Stdout, "mod-sum: %s\n", info.ModSum)
+				}
+				return nil
+			}
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+type Shape interface {
+	IsShape()
+}
+
+type Circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (Circle) IsShape() {}
+
+type Square struct {
+	Side float64 `json:"side"`
+}
+
+func (*Square) IsShape() {}
+
+type Event interface {
+	IsEvent()
+}
+
+type DrawnEvent struct {
+	Shape  Shape   `json:"shape"`
+	Shapes []Shape `json:"shapes"`
+}
+
+func (DrawnEvent) IsEvent() {}
+
+type ClearedEvent struct{}
+
+func (ClearedEvent) IsEvent() {}
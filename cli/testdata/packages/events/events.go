@@ -0,0 +1,20 @@
+package events
+
+import "github.com/eugenenosenko/gopoly/cli/testdata/packages/shapes"
+
+type Event interface {
+	IsEvent()
+}
+
+type DrawnEvent struct {
+	Type  string       `json:"type"`
+	Shape shapes.Shape `json:"shape"`
+}
+
+func (DrawnEvent) IsEvent() {}
+
+type ClearedEvent struct {
+	Type string `json:"type"`
+}
+
+func (ClearedEvent) IsEvent() {}
@@ -0,0 +1,17 @@
+package shapes
+
+type Shape interface {
+	IsShape()
+}
+
+type Square struct {
+	Side int `json:"side"`
+}
+
+func (Square) IsShape() {}
+
+type Circle struct {
+	Radius int `json:"radius"`
+}
+
+func (Circle) IsShape() {}
@@ -0,0 +1,17 @@
+// Command gopoly-vet runs the gopoly analyzer as a vet tool:
+//
+//	go vet -vettool=$(which gopoly-vet) -gopoly.config=$PWD/.gopoly.yaml ./...
+//
+// Config paths should be absolute since the vet tool is run in the directory of each package, several config
+// files are separated by commas.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/eugenenosenko/gopoly/lint"
+)
+
+func main() {
+	unitchecker.Main(lint.NewAnalyzer(nil))
+}
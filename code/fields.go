@@ -0,0 +1,106 @@
+package code
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONField is a field of the Struct as seen by encoding/json.
+type JSONField struct {
+	// Name of the field in the payload, taken from the json tag or the field name.
+	Name      string
+	OmitEmpty bool
+	Field     *Field
+}
+
+// JSONFields returns fields of the struct that are encoded by encoding/json, in the declaration order. Fields of the
+// embedded structs without a JSON name are promoted, fields shadowed by the name are skipped.
+func (s *Struct) JSONFields() []*JSONField {
+	res := make([]*JSONField, 0)
+	if s == nil {
+		return res
+	}
+	seen := make(map[string]struct{}, 0)
+	var collect func(fields FieldList)
+	collect = func(fields FieldList) {
+		for _, f := range fields {
+			name, omitempty, ok := jsonTag(f)
+			if !ok {
+				continue
+			}
+			if f.Embedded && name == "" {
+				if st := f.Type.embedded(); st != nil {
+					collect(st.Fields)
+					continue
+				}
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			res = append(res, &JSONField{Name: name, OmitEmpty: omitempty, Field: f})
+		}
+	}
+	collect(s.Fields)
+	return res
+}
+
+// IsBytes is true for []byte, encoded as base64 string by encoding/json.
+func (r *TypeRef) IsBytes() bool {
+	return r != nil && r.Kind == RefSlice && r.Elem != nil && r.Elem.Kind == RefIdent && r.Elem.Pkg == "" &&
+		(r.Elem.Name == "byte" || r.Elem.Name == "uint8")
+}
+
+// Basic returns the predeclared type of the identifier, underlying type for the named basic types. Empty for
+// the other types.
+func (r *TypeRef) Basic() string {
+	switch {
+	case r == nil:
+		return ""
+	case r.Underlying != "":
+		return r.Underlying
+	case r.Pkg == "":
+		return r.Name
+	default:
+		return ""
+	}
+}
+
+// embedded returns the struct of the embedded field, nil if it's not a struct of the same package.
+func (r *TypeRef) embedded() *Struct {
+	if r != nil && r.Kind == RefPointer {
+		r = r.Elem
+	}
+	if r == nil {
+		return nil
+	}
+	return r.Struct
+}
+
+// jsonTag returns JSON name of the field from its json tag and whether it's omitted when empty.
+// Returns false for the fields skipped by encoding/json.
+func jsonTag(f *Field) (name string, omitempty bool, ok bool) {
+	if !f.Embedded && !isExported(f.Name) {
+		return "", false, false
+	}
+	tag := reflect.StructTag(strings.Trim(f.Tags, "`")).Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		omitempty = omitempty || opt == "omitempty"
+	}
+	name = parts[0]
+	if f.Embedded && name == "" && f.Type.embedded() == nil && !isExported(f.Name) {
+		return "", false, false
+	}
+	return name, omitempty, true
+}
+
+func isExported(name string) bool {
+	return name != "" && strings.ToUpper(name[:1]) == name[:1]
+}
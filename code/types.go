@@ -1,8 +1,8 @@
 package code
 
 import (
-	"go/build"
 	"path"
+	"sort"
 
 	"github.com/eugenenosenko/gopoly/internal/xslices"
 )
@@ -32,6 +32,11 @@ type Variant struct {
 	Name      string
 	Fields    PolyFieldList
 	Interface *Interface
+	// PointerReceiver is true when the marker method is declared on the pointer receiver,
+	// in which case only a pointer to the Variant implements the Interface.
+	PointerReceiver bool
+	// Struct is the declaration of the Variant, nil if Variant is not a struct.
+	Struct *Struct
 }
 
 type Interface struct {
@@ -39,6 +44,88 @@ type Interface struct {
 	MarkerMethod string
 	Variants     VariantList
 	Pkg          string
+	// Discriminator is nil for interfaces that are not decoded with the discriminator.
+	Discriminator *Discriminator
+}
+
+// MappedVariants returns variants of the Interface sorted by name. Variants of discriminated interfaces without
+// discriminator values are left out since they can't be decoded.
+func (i *Interface) MappedVariants() VariantList {
+	res := make(VariantList, 0, len(i.Variants))
+	for _, v := range i.Variants {
+		if i.Discriminator == nil || len(i.Discriminator.Tags(v.Name)) > 0 {
+			res = append(res, v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Discriminator describes how variants of the Interface are identified in the payload.
+type Discriminator struct {
+	Field string
+	// Mapping of discriminator values to the variant names.
+	Mapping map[string]string
+}
+
+// Tags returns sorted discriminator values mapped to the variant.
+func (d *Discriminator) Tags(variant string) []string {
+	var res []string
+	for tag, name := range d.Mapping {
+		if name == variant {
+			res = append(res, tag)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+type FieldList []*Field
+
+// Field of the Struct declaration.
+type Field struct {
+	Name     string
+	Tags     string
+	Type     *TypeRef
+	Embedded bool
+}
+
+// Struct is a struct type declaration.
+type Struct struct {
+	Name   string
+	Pkg    string
+	Fields FieldList
+}
+
+type TypeRefKind int
+
+const (
+	RefIdent TypeRefKind = iota
+	RefPointer
+	RefSlice
+	RefMap
+	RefOther
+)
+
+// TypeRef describes the type expression of a Field.
+type TypeRef struct {
+	Kind TypeRefKind
+	// Name of the identifier, i.e. string, Time, User.
+	Name string
+	// Pkg is the import path of the imported identifier, empty for the identifiers of the same package
+	// and predeclared types.
+	Pkg string
+	// Elem is the element type of pointers, slices and map values.
+	Elem *TypeRef
+	// Struct is the resolved struct declaration of the identifier from the same package.
+	Struct *Struct
+	// Interface is the resolved configured interface of the identifier.
+	Interface *Interface
+	// Underlying is the predeclared type of the identifier declared by the loaded packages as a named
+	// basic type, i.e. string for type Kind string. Empty for the predeclared types themselves.
+	Underlying string
 }
 
 func (vvs VariantList) AssociateByVariantName() map[string]*Variant {
@@ -63,22 +150,12 @@ func (p Package) Name() string {
 	return path.Base(p.Path())
 }
 
-func (p Package) Dir() string {
-	return packageNameToDir(p.Path())
-}
-
-func packageNameToDir(path string) string {
-	p, err := build.Default.Import(path, "", build.FindOnly)
-	if err != nil {
-		panic(err)
-	}
-	return p.Dir
-}
-
 type SourceList []*Source
 
 type Source struct {
-	Package    Package
+	Package Package
+	// Dir is the directory of the Package, empty when the loaded package has no directory.
+	Dir        string
 	Interfaces InterfaceList
 	Imports    ImportList
 }
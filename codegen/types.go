@@ -11,6 +11,13 @@ type Task struct {
 	// Filename output file
 	Filename string
 
+	// Package the output file belongs to.
+	Package code.Package
+
+	// Dir is the directory of the Package the output file is written to, it's resolved from the Package
+	// when empty.
+	Dir string
+
 	// Template string that is then parsed into template.Template
 	Template string
 
@@ -24,6 +31,9 @@ type Input struct {
 	Package string
 	Imports []*code.Import
 	Types   []*Type
+
+	// Options of the target the Input is generated for, i.e. go_package of the proto target.
+	Options map[string]string
 }
 
 // Type represents an interface for which unmarshal method needs to be created
@@ -32,4 +42,14 @@ type Type struct {
 	Variants           map[string]*code.Variant
 	DecodingStrategy   string
 	DiscriminatorField string
+
+	// Registry when set, discriminator switch is backed by a registry that allows
+	// registering additional variants at run-time.
+	Registry bool
+
+	// SQLColumn when set, a column type storing the interface as JSON in the database is generated.
+	SQLColumn bool
+
+	// Interface is the source declaration of the type.
+	Interface *code.Interface
 }
@@ -3,7 +3,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
-	"go/build"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/eugenenosenko/gopoly/internal/xslices"
 )
@@ -23,8 +24,33 @@ func (s DecodingStrategy) IsStrict() bool {
 }
 
 func (s DecodingStrategy) IsValid() bool {
-	switch s {
-	case DecodingStrategyStrict, DecodingStrategyDiscriminator:
+	return slices.Contains(DecodingStrategies, s)
+}
+
+const (
+	DecodingStrategyStrict        = DecodingStrategy("strict")
+	DecodingStrategyDiscriminator = DecodingStrategy("discriminator")
+)
+
+// DecodingStrategies are the valid decoding strategies.
+var DecodingStrategies = []DecodingStrategy{DecodingStrategyStrict, DecodingStrategyDiscriminator}
+
+// TargetKind is the kind of the additional output generated from the types, i.e. JSON schema.
+type TargetKind string
+
+func (k TargetKind) String() string {
+	return string(k)
+}
+
+func (k TargetKind) IsValid() bool {
+	return slices.Contains(TargetKinds, k)
+}
+
+// IsGoSource reports whether the target is Go code generated in the package of the type, i.e. MessagePack
+// decoders. Filename of such target is relative to the directory of the package.
+func (k TargetKind) IsGoSource() bool {
+	switch k {
+	case TargetKindMsgPack, TargetKindCBOR, TargetKindBSON, TargetKindXML:
 		return true
 	default:
 		return false
@@ -32,18 +58,61 @@ func (s DecodingStrategy) IsValid() bool {
 }
 
 const (
-	DecodingStrategyStrict        = DecodingStrategy("strict")
-	DecodingStrategyDiscriminator = DecodingStrategy("discriminator")
+	TargetKindJSONSchema = TargetKind("jsonschema")
+	TargetKindTypeScript = TargetKind("typescript")
+	TargetKindGraphQL    = TargetKind("graphql")
+	TargetKindProto      = TargetKind("proto")
+	TargetKindMsgPack    = TargetKind("msgpack")
+	TargetKindCBOR       = TargetKind("cbor")
+	TargetKindBSON       = TargetKind("bson")
+	TargetKindXML        = TargetKind("xml")
+)
+
+// TargetKinds are the valid target kinds.
+var TargetKinds = []TargetKind{
+	TargetKindJSONSchema, TargetKindTypeScript, TargetKindGraphQL, TargetKindProto,
+	TargetKindMsgPack, TargetKindCBOR, TargetKindBSON, TargetKindXML,
+}
+
+// Options of the proto target.
+const (
+	// ProtoOptionPackage is the package of the .proto file, defaults to the name of the go_package.
+	ProtoOptionPackage = "package"
+	// ProtoOptionGoPackage is the go_package of the .proto file. When set, Go functions converting the types
+	// to the messages generated by protoc-gen-go and back are generated in the package of the types.
+	ProtoOptionGoPackage = "go_package"
+	// ProtoOptionGoFilename is the file of the conversion functions, defaults to proto.gen.go.
+	ProtoOptionGoFilename = "go_filename"
+	// ProtoOptionOneof lists the numbers of the oneof fields of the variants of the type, i.e.
+	// BannedUser:1,RegularUser:2. Every variant has to be numbered.
+	ProtoOptionOneof = "oneof"
 )
 
+// XMLOptionAttribute is the option of the xml target naming the attribute that selects the variant,
+// i.e. xsi:type. Variants are selected by the name of the element when it's not set.
+const XMLOptionAttribute = "attribute"
+
+// TargetConfig describes an additional output generated for the type. Filename is relative to the
+// working directory and can be a template, i.e. schemas/{{ .Name }}.json. Filename of the Go source
+// targets is relative to the package of the type.
+type TargetConfig struct {
+	Kind     TargetKind `yaml:"kind" doc:"kind of the output"`
+	Filename string     `yaml:"filename" doc:"output filename, can be a template, i.e. schemas/{{ .Name }}.json"`
+	// Options specific to the kind of the target, i.e. go_package of the proto target.
+	Options map[string]string `yaml:"options,omitempty" doc:"options specific to the kind of the target"`
+}
+
 type TypeDefinition struct {
-	Name             string                  `yaml:"name"`
-	Variants         []string                `yaml:"variants,omitempty"`
-	MarkerMethod     string                  `yaml:"marker_method,omitempty"`
-	DecodingStrategy DecodingStrategy        `yaml:"decoding_strategy,omitempty"`
-	Discriminator    DiscriminatorDefinition `yaml:"discriminator,omitempty"`
-	Package          string                  `yaml:"package,omitempty"`
-	Output           *OutputConfig           `yaml:"output,omitempty"`
+	Name             string                  `yaml:"name" doc:"name of the interface"`
+	Variants         []string                `yaml:"variants,omitempty" doc:"types implementing the interface"`
+	MarkerMethod     string                  `yaml:"marker_method,omitempty" doc:"marker method of the interface, can be a template"`
+	DecodingStrategy DecodingStrategy        `yaml:"decoding_strategy,omitempty" doc:"how variants are decoded"`
+	Discriminator    DiscriminatorDefinition `yaml:"discriminator,omitempty" doc:"discriminator identifying the variants"`
+	Package          string                  `yaml:"package,omitempty" doc:"import path of the package of the interface or its path relative to the config file, i.e. ./models"`
+	Output           *OutputConfig           `yaml:"output,omitempty" doc:"output of the generated code"`
+	Registry         bool                    `yaml:"registry,omitempty" doc:"back discriminator decoding with a run-time registry"`
+	SQLColumn        bool                    `yaml:"sql_column,omitempty" doc:"generate sql.Scanner and driver.Valuer column type"`
+	Targets          []*TargetConfig         `yaml:"targets,omitempty" doc:"additional outputs generated from the interface"`
 }
 
 type Package string
@@ -56,20 +125,8 @@ func (p Package) Name() string {
 	return string(p)
 }
 
-func (p Package) Dir() string {
-	return packageNameToDir(p.Name())
-}
-
-func packageNameToDir(path string) string {
-	p, err := build.Default.Import(path, "", build.FindOnly)
-	if err != nil {
-		panic(err)
-	}
-	return p.Dir
-}
-
 type OutputConfig struct {
-	Filename string `yaml:"filename"`
+	Filename string `yaml:"filename" doc:"filename of the generated code in the package of the interface"`
 }
 
 type TypesList []*TypeDefinition
@@ -84,12 +141,31 @@ func (tts TypesList) AssociateByTypeName() map[string]*TypeDefinition {
 	)
 }
 
+// Defaults of the configuration, see Config.SetDefaults.
+const (
+	DefaultMarkerMethod = "Is{{.Name}}"
+	DefaultOutputFile   = "gopoly.gen.go"
+)
+
+// Version is the latest version of the configuration format. Configuration without version is of the version 1.
+const Version = 1
+
 type Config struct {
-	Types            TypesList        `yaml:"types"`
-	DecodingStrategy DecodingStrategy `yaml:"decoding_strategy"`
-	MarkerMethod     string           `yaml:"marker_method"`
-	Output           *OutputConfig    `yaml:"output"`
-	Package          string           `yaml:"package"`
+	// Version of the configuration format, configuration of the newer version than Version is rejected.
+	Version          int              `yaml:"version,omitempty" doc:"version of the configuration format"`
+	Types            TypesList        `yaml:"types" doc:"configured interfaces"`
+	DecodingStrategy DecodingStrategy `yaml:"decoding_strategy" doc:"default decoding strategy of the interfaces"`
+	MarkerMethod     string           `yaml:"marker_method" doc:"default marker method of the interfaces, can be a template"`
+	Output           *OutputConfig    `yaml:"output" doc:"default output of the generated code"`
+	Package          string           `yaml:"package" doc:"default import path of the package of the interfaces or its path relative to the config file"`
+	Targets          []*TargetConfig  `yaml:"targets,omitempty" doc:"default additional outputs generated from the interfaces"`
+	// FromOpenAPI is the OpenAPI document, relative to the config file, types are imported from.
+	FromOpenAPI string `yaml:"from_openapi,omitempty" doc:"OpenAPI document, relative to the config file, to import types from"`
+	// FromGQLGen is the gqlgen.yml, relative to the config file, types are imported from.
+	FromGQLGen string `yaml:"from_gqlgen,omitempty" doc:"gqlgen.yml, relative to the config file, to import types from"`
+	// Include are the config files, relative to the config file, whose types are included, i.e. config files
+	// next to the packages of the types. Settings of the included file apply to its own types only.
+	Include []string `yaml:"include,omitempty" doc:"config files, relative to the config file, whose types are included"`
 }
 
 func (tts TypesList) AssociateByPkgName() map[string]TypesList {
@@ -109,8 +185,8 @@ func (tts TypesList) AssociateByOutput() map[string]TypesList {
 }
 
 type DiscriminatorDefinition struct {
-	Field   string            `yaml:"field"`
-	Mapping map[string]string `yaml:"mapping"`
+	Field   string            `yaml:"field" doc:"field of the payload identifying the variant"`
+	Mapping map[string]string `yaml:"mapping" doc:"variants by the values of the discriminator field"`
 }
 
 func (c *Config) String() string {
@@ -124,5 +200,6 @@ func (c *Config) String() string {
 var (
 	_ fmt.Stringer = (*Config)(nil)
 	_ fmt.Stringer = (*DecodingStrategy)(nil)
+	_ fmt.Stringer = (*TargetKind)(nil)
 	_ fmt.Stringer = (*Package)(nil)
 )
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -53,3 +54,392 @@ func TestConfig_New(t *testing.T) {
 		}, &c)
 	})
 }
+
+func TestNewFromYAML(t *testing.T) {
+	t.Run("should resolve package paths relative to the config file", func(t *testing.T) {
+		c, err := NewFromYAML("testdata/relative.yaml")
+		require.NoError(t, err)
+		require.Equal(t, "github.com/eugenenosenko/gopoly/config/testdata/discover/events", c.Package)
+		require.Equal(t, "", c.Types[0].Package)
+		require.Equal(t, "github.com/eugenenosenko/gopoly/config/testdata/discover/shapes", c.Types[1].Package)
+		require.Equal(t, "github.com/username/example/animals", c.Types[2].Package)
+
+		pkg, err := ResolvePackage("testdata", "./discover/shapes")
+		require.NoError(t, err)
+		require.Equal(t, "github.com/eugenenosenko/gopoly/config/testdata/discover/shapes", pkg)
+
+		_, err = ResolvePackage(os.TempDir(), "./models")
+		require.ErrorContains(t, err, "resolving package ./models")
+	})
+
+	t.Run("should import types from OpenAPI document without overriding configured ones", func(t *testing.T) {
+		r := Reader{OpenAPI: func(filename string) (TypesList, error) {
+			require.Equal(t, filepath.Join("testdata", "api.yaml"), filename)
+			return TypesList{
+				{Name: "Contact", Variants: []string{"EmailContact", "PhoneContact"}, DecodingStrategy: DecodingStrategyStrict},
+				{
+					Name:             "UserEvent",
+					Variants:         []string{"UserCreated", "UserDeleted"},
+					DecodingStrategy: DecodingStrategyDiscriminator,
+					Discriminator: DiscriminatorDefinition{
+						Field:   "type",
+						Mapping: map[string]string{"CREATED": "UserCreated", "user-deleted": "UserDeleted"},
+					},
+				},
+			}, nil
+		}}
+		c, err := r.NewFromYAML("testdata/openapi_config.yaml")
+		require.NoError(t, err)
+		require.NoError(t, c.Normalize())
+
+		types := c.Types.AssociateByTypeName()
+		require.Len(t, types, 2)
+		require.Equal(t, "IsContactType", types["Contact"].MarkerMethod)
+		require.Equal(t, &TypeDefinition{
+			Name:             "UserEvent",
+			Variants:         []string{"UserCreated", "UserDeleted"},
+			MarkerMethod:     "IsUserEvent",
+			DecodingStrategy: DecodingStrategyDiscriminator,
+			Discriminator: DiscriminatorDefinition{
+				Field:   "type",
+				Mapping: map[string]string{"CREATED": "UserCreated", "user-deleted": "UserDeleted"},
+			},
+			Package: "github.com/username/example/api",
+			Output:  &OutputConfig{Filename: "gopoly.gen.go"},
+		}, types["UserEvent"])
+	})
+
+	t.Run("should reject importing types without the importer", func(t *testing.T) {
+		_, err := NewFromYAML("testdata/openapi_config.yaml")
+		require.EqualError(t, err, "config.NewFromYAML: from_openapi of testdata/openapi_config.yaml isn't supported")
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should include types with the settings of the included config files", func(t *testing.T) {
+		c, err := Load("testdata/include/.gopoly.yaml")
+		require.NoError(t, err)
+		require.NoError(t, c.Normalize())
+
+		require.Equal(t, TypesList{
+			{
+				Name:             "Animal",
+				Variants:         []string{"Cat", "Dog"},
+				MarkerMethod:     "IsAnimal",
+				DecodingStrategy: DecodingStrategyStrict,
+				Package:          "github.com/username/example/animals",
+				Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+			},
+			{
+				Name:             "Event",
+				MarkerMethod:     "IsEvent",
+				DecodingStrategy: DecodingStrategyDiscriminator,
+				Discriminator: DiscriminatorDefinition{
+					Field:   "type",
+					Mapping: map[string]string{"CREATED": "CreatedEvent"},
+				},
+				Package: "github.com/username/example/events",
+				Output:  &OutputConfig{Filename: "events.gen.go"},
+			},
+			{
+				Name:             "Shape",
+				Variants:         []string{"Square", "Circle"},
+				MarkerMethod:     "IsShape",
+				DecodingStrategy: DecodingStrategyStrict,
+				Package:          "github.com/username/example/shapes",
+				Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+			},
+		}, c.Types)
+	})
+
+	t.Run("should reject config files including themselves", func(t *testing.T) {
+		_, err := Load("testdata/include/cycle.yaml")
+		require.EqualError(t, err, "config.NewFromYAML: testdata/include/cycle.yaml includes itself via "+
+			"testdata/include/cycle.yaml -> testdata/include/cycle_included.yaml -> testdata/include/cycle.yaml")
+	})
+
+	t.Run("should override settings and types of the earlier config files", func(t *testing.T) {
+		c, err := Load("testdata/test_config.yaml", "testdata/include/shapes/.gopoly.yaml")
+		require.NoError(t, err)
+		require.Equal(t, "github.com/username/example/shapes", c.Package)
+		require.Equal(t, DecodingStrategyStrict, c.DecodingStrategy)
+		require.Len(t, c.Types, 4)
+		require.Equal(t, []string{"Square", "Circle"}, c.Types.AssociateByTypeName()["Shape"].Variants)
+	})
+
+	t.Run("should identify types by their name and package", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name, data string) string {
+			filename := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(filename, []byte(data), 0o644))
+			return filename
+		}
+		write("audit.yaml", `
+package: "github.com/username/example/audit"
+types:
+  - name: "Event"
+`)
+		write("events.yaml", `
+types:
+  - name: "Event"
+    package: "github.com/username/example/events"
+`)
+		main := write("main.yaml", `
+package: "github.com/username/example/events"
+include:
+  - "audit.yaml"
+types:
+  - name: "Event"
+    variants: ["CreatedEvent"]
+`)
+		c, err := Load(main)
+		require.NoError(t, err)
+		require.Len(t, c.Types, 2)
+
+		_, err = Load(write("duplicate.yaml", `
+package: "github.com/username/example/events"
+include:
+  - "events.yaml"
+types:
+  - name: "Event"
+`))
+		require.ErrorContains(t, err, "type Event of "+filepath.Join(dir, "events.yaml")+" is already defined")
+
+		c, err = Load(main, write("override.yaml", `
+types:
+  - name: "Event"
+    package: "github.com/username/example/events"
+    variants: ["DeletedEvent"]
+`))
+		require.NoError(t, err)
+		require.Len(t, c.Types, 2)
+		require.Equal(t, []string{"DeletedEvent"}, c.Types[0].Variants)
+		require.Equal(t, "github.com/username/example/audit", c.Types[1].Package)
+	})
+
+	t.Run("should keep the package of the config file of the merged types", func(t *testing.T) {
+		dir := t.TempDir()
+		a := filepath.Join(dir, "a.yaml")
+		require.NoError(t, os.WriteFile(a, []byte(`
+package: "github.com/username/example/a"
+types:
+  - name: "X"
+`), 0o644))
+		b := filepath.Join(dir, "b.yaml")
+		require.NoError(t, os.WriteFile(b, []byte(`
+package: "github.com/username/example/b"
+types:
+  - name: "Y"
+  - name: "X"
+`), 0o644))
+
+		c, err := Load(a, b)
+		require.NoError(t, err)
+		c.SetDefaults()
+		require.NoError(t, c.Normalize())
+		require.Equal(t, "github.com/username/example/b", c.Package)
+		require.Len(t, c.Types, 3)
+		require.Equal(t, "github.com/username/example/a", c.Types[0].Package)
+		require.Equal(t, "github.com/username/example/b", c.Types[1].Package)
+		require.Equal(t, "Y", c.Types[1].Name)
+		require.Equal(t, "github.com/username/example/b", c.Types[2].Package)
+		require.Equal(t, "X", c.Types[2].Name)
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Run("should find config file in the parent directories up to the module root", func(t *testing.T) {
+		root := t.TempDir()
+		dir := filepath.Join(root, "module", "models", "events")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "module", "go.mod"), []byte("module example\n"), 0o644))
+
+		filename, err := Find(dir, ".gopoly.yaml")
+		require.NoError(t, err)
+		require.Empty(t, filename)
+
+		// config files outside the module are not looked at
+		require.NoError(t, os.WriteFile(filepath.Join(root, ".gopoly.yaml"), nil, 0o644))
+		filename, err = Find(dir, ".gopoly.yaml")
+		require.NoError(t, err)
+		require.Empty(t, filename)
+
+		expected := filepath.Join(root, "module", "models", ".gopoly.yaml")
+		require.NoError(t, os.WriteFile(expected, nil, 0o644))
+		filename, err = Find(dir, ".gopoly.yaml")
+		require.NoError(t, err)
+		require.Equal(t, expected, filename)
+	})
+}
+
+func TestDiscover(t *testing.T) {
+	t.Run("should find config files next to the packages matching the patterns", func(t *testing.T) {
+		files, err := Discover(".gopoly.yaml", "./testdata/discover/...")
+		require.NoError(t, err)
+		require.Equal(t, []*File{
+			{
+				Filename: filepath.Join("testdata", "discover", "events", ".gopoly.yaml"),
+				Package:  "github.com/eugenenosenko/gopoly/config/testdata/discover/events",
+			},
+			{
+				Filename: filepath.Join("testdata", "discover", "shapes", ".gopoly.yaml"),
+				Package:  "github.com/eugenenosenko/gopoly/config/testdata/discover/shapes",
+			},
+		}, files)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("should report all problems with their positions", func(t *testing.T) {
+		_, err := NewFromYAML("testdata/invalid_config.yaml")
+
+		var problems Problems
+		require.ErrorAs(t, err, &problems)
+		require.Equal(t, `testdata/invalid_config.yaml:3:24: type Event: can't have discriminator decoding and empty mapping
+testdata/invalid_config.yaml:8:24: type Shape: can't have discriminator mapping & strict decoding
+testdata/invalid_config.yaml:13:21: type Shape: discriminator mapping value Triangle is not a variant
+testdata/invalid_config.yaml:14:5: unknown key filename in type Shape
+testdata/invalid_config.yaml:15:11: duplicate type Event, first defined at line 2
+testdata/invalid_config.yaml:16:24: type Event: not a valid decoding-strategy dynamic
+testdata/invalid_config.yaml:18:15: type Animal: can't have registry without discriminator decoding
+testdata/invalid_config.yaml:19:13: type Animal: output without filename
+testdata/invalid_config.yaml:21:9: type Animal: target without filename
+testdata/invalid_config.yaml:21:15: type Animal: not a valid target kind avro
+testdata/invalid_config.yaml:22:15: type Animal: can't have cbor target without discriminator decoding
+testdata/invalid_config.yaml:24:9: unknown key format in type Animal target
+testdata/invalid_config.yaml:25:5: type without name
+testdata/invalid_config.yaml:27:1: unknown key pakage in config`, err.Error())
+	})
+
+	t.Run("should reject unsupported versions", func(t *testing.T) {
+		var doc yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte("version: 2\ntypes: []\n"), &doc))
+		require.Equal(t, "config.yaml:1:10: unsupported config version 2, latest supported version is 1",
+			Validate("config.yaml", &doc).Error())
+	})
+
+	t.Run("should accept valid configuration", func(t *testing.T) {
+		var doc yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte(`
+version: 1
+types:
+  - name: "Event"
+    variants: ["Created"]
+    discriminator:
+      mapping:
+        "CREATED": "Created"
+    targets:
+      - kind: "msgpack"
+        filename: "event_msgpack.gen.go"
+`), &doc))
+		require.Empty(t, Validate("config.yaml", &doc))
+	})
+
+	t.Run("should report duplicate types of the same package only", func(t *testing.T) {
+		var doc yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte(`
+package: "github.com/username/example/events"
+types:
+  - name: "Event"
+  - name: "Event"
+    package: "github.com/username/example/audit"
+  - name: "Event"
+    package: "github.com/username/example/events"
+`), &doc))
+		require.Equal(t, "config.yaml:7:11: duplicate type Event, first defined at line 4",
+			Validate("config.yaml", &doc).Error())
+	})
+}
+
+func TestConfig_Normalize(t *testing.T) {
+	t.Run("should propagate parent configuration and expand marker-method templates", func(t *testing.T) {
+		c := &Config{
+			Types: TypesList{
+				{Name: "Runner"},
+				{
+					Name:         "Owner",
+					MarkerMethod: "IsOwnerType",
+					Package:      "github.com/username/example/owners",
+					Discriminator: DiscriminatorDefinition{
+						Field:   "kind",
+						Mapping: map[string]string{"AGENCY": "AgencyOwner"},
+					},
+				},
+			},
+			DecodingStrategy: DecodingStrategyStrict,
+			MarkerMethod:     "Is{{ .Name }}",
+			Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+			Package:          "github.com/username/example/models",
+		}
+
+		require.NoError(t, c.Normalize())
+		require.Equal(t, &TypeDefinition{
+			Name:             "Runner",
+			MarkerMethod:     "IsRunner",
+			DecodingStrategy: DecodingStrategyStrict,
+			Package:          "github.com/username/example/models",
+			Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+		}, c.Types[0])
+		require.Equal(t, "IsOwnerType", c.Types[1].MarkerMethod)
+		require.Equal(t, DecodingStrategyDiscriminator, c.Types[1].DecodingStrategy)
+		require.Equal(t, "github.com/username/example/owners", c.Types[1].Package)
+	})
+
+	t.Run("should reject discriminator mapping with strict decoding", func(t *testing.T) {
+		c := &Config{
+			Types: TypesList{
+				{
+					Name:             "Owner",
+					DecodingStrategy: DecodingStrategyStrict,
+					Discriminator: DiscriminatorDefinition{
+						Field:   "kind",
+						Mapping: map[string]string{"AGENCY": "AgencyOwner"},
+					},
+				},
+			},
+		}
+
+		require.EqualError(t, c.Normalize(), "type Owner: can't have discriminator mapping & strict decoding")
+	})
+
+	t.Run("should propagate targets and expand their filename templates", func(t *testing.T) {
+		c := &Config{
+			Types: TypesList{
+				{Name: "Runner"},
+				{Name: "Owner", Targets: []*TargetConfig{{Kind: TargetKindJSONSchema, Filename: "owner.json"}}},
+			},
+			DecodingStrategy: DecodingStrategyStrict,
+			MarkerMethod:     "Is{{ .Name }}",
+			Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+			Package:          "github.com/username/example/models",
+			Targets:          []*TargetConfig{{Kind: TargetKindJSONSchema, Filename: "schemas/{{ .Name }}.json"}},
+		}
+
+		require.NoError(t, c.Normalize())
+		require.Equal(t, []*TargetConfig{{Kind: TargetKindJSONSchema, Filename: "schemas/Runner.json"}}, c.Types[0].Targets)
+		require.Equal(t, []*TargetConfig{{Kind: TargetKindJSONSchema, Filename: "owner.json"}}, c.Types[1].Targets)
+		require.Equal(t, "schemas/{{ .Name }}.json", c.Targets[0].Filename)
+	})
+
+	t.Run("should reject unknown target kind", func(t *testing.T) {
+		c := &Config{
+			Types:            TypesList{{Name: "Owner", Targets: []*TargetConfig{{Kind: "avro", Filename: "owner.avsc"}}}},
+			DecodingStrategy: DecodingStrategyStrict,
+			Package:          "github.com/username/example/models",
+			Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+		}
+
+		require.EqualError(t, c.Normalize(), "type Owner: not a valid target kind avro")
+	})
+
+	t.Run("should reject binary decoding targets of strictly decoded types", func(t *testing.T) {
+		c := &Config{
+			Types:            TypesList{{Name: "Owner", Targets: []*TargetConfig{{Kind: TargetKindCBOR, Filename: "cbor.gen.go"}}}},
+			DecodingStrategy: DecodingStrategyStrict,
+			Package:          "github.com/username/example/models",
+			Output:           &OutputConfig{Filename: "gopoly.gen.go"},
+		}
+
+		require.EqualError(t, c.Normalize(), "type Owner: can't have cbor target without discriminator decoding")
+	})
+}
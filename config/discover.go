@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/eugenenosenko/gopoly/internal/xfs"
+	"github.com/eugenenosenko/gopoly/internal/xmod"
+)
+
+// File is a config file found in the directory of a package.
+type File struct {
+	// Filename of the config file, relative to the working directory when it's inside of it.
+	Filename string
+	// Package is the import path of the package in the directory of the config file.
+	Package string
+}
+
+// Discover returns the config files named name that are found in the directories of the packages matching
+// the patterns, i.e. ./..., sorted by their filename.
+func Discover(name string, patterns ...string) ([]*File, error) {
+	packs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config.Discover: loading packages %v", patterns)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.Wrap(err, "config.Discover: getting working directory")
+	}
+
+	res := make([]*File, 0)
+	for _, pack := range packs {
+		dir := xmod.PackageDir(pack)
+		if dir == "" {
+			continue
+		}
+		filename := filepath.Join(dir, name)
+		if !xfs.FileExists(filename) {
+			continue
+		}
+		if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			filename = rel
+		}
+		res = append(res, &File{Filename: filename, Package: pack.PkgPath})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Filename < res[j].Filename
+	})
+	return res, nil
+}
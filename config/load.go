@@ -0,0 +1,367 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eugenenosenko/gopoly/internal/xfs"
+	"github.com/eugenenosenko/gopoly/internal/xmod"
+)
+
+// Importer returns type definitions imported from the file, i.e. from the OpenAPI document.
+type Importer func(filename string) (TypesList, error)
+
+// Reader reads config files importing the types of the from_openapi and from_gqlgen settings with its
+// importers. Config files with the setting whose importer is nil can't be read.
+type Reader struct {
+	OpenAPI Importer
+	GQLGen  Importer
+}
+
+// NewFromYAML reads Config from the YAML file along with the types of the config files it includes, see
+// Reader.NewFromYAML. Config files importing types can't be read, use Reader with the importers instead.
+func NewFromYAML(filename string) (*Config, error) {
+	return Reader{}.NewFromYAML(filename)
+}
+
+// Load reads and merges the config files in order, see Reader.Load. Config files importing types can't
+// be read, use Reader with the importers instead.
+func Load(filenames ...string) (*Config, error) {
+	return Reader{}.Load(filenames...)
+}
+
+// NewFromYAML reads Config from the YAML file along with the types of the config files it includes. Returns Problems
+// of the file when it doesn't pass Validate. Returned Config is not normalized.
+func (r Reader) NewFromYAML(filename string) (*Config, error) {
+	return r.newFromYAML(filename, nil)
+}
+
+// Load reads and merges the config files in order, settings and types of the later files override the ones of
+// the earlier files. Returned Config is not normalized.
+func (r Reader) Load(filenames ...string) (*Config, error) {
+	res := &Config{Types: TypesList{}}
+	for _, filename := range filenames {
+		c, err := r.NewFromYAML(filename)
+		if err != nil {
+			return nil, err
+		}
+		res.Merge(c)
+	}
+	return res, nil
+}
+
+// ResolvePackage returns import path of the package path relative to dir, i.e. ./models, based on the go.mod
+// of the module the package belongs to. Import paths are returned as is.
+func ResolvePackage(dir, pkg string) (string, error) {
+	if !xmod.IsRelative(pkg) {
+		return pkg, nil
+	}
+	res, err := xmod.ImportPath(filepath.Join(dir, filepath.FromSlash(pkg)))
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving package %s", pkg)
+	}
+	return res, nil
+}
+
+// Find looks for the config file named name in dir and its parent directories up to the root of the module,
+// the directory with go.mod. Only dir is looked at when it's not in a module. Returns empty filename when
+// there is no such config file.
+func Find(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "config.Find: resolving directory %s", dir)
+	}
+	root := dir
+	for d := dir; ; d = filepath.Dir(d) {
+		if xfs.FileExists(filepath.Join(d, "go.mod")) {
+			root = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		if filename := filepath.Join(d, name); xfs.FileExists(filename) {
+			return filename, nil
+		}
+		if d == root || filepath.Dir(d) == d {
+			return "", nil
+		}
+	}
+}
+
+// newFromYAML reads Config from the YAML file, including is the chain of the files that include it.
+func (r Reader) newFromYAML(filename string, including []string) (*Config, error) {
+	if slices.Contains(including, filepath.Clean(filename)) {
+		return nil, errors.Errorf("config.NewFromYAML: %s includes itself via %s",
+			filename, strings.Join(append(including, filename), " -> "))
+	}
+	including = append(including, filepath.Clean(filename))
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config.NewFromYAML: reading filename %s", filename)
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "config.NewFromYAML: unmarshaling filename %s", filename)
+	}
+	if problems := Validate(filename, &doc); len(problems) > 0 {
+		return nil, problems
+	}
+	var c Config
+	if doc.Kind != 0 {
+		if err = doc.Decode(&c); err != nil {
+			return nil, errors.Wrapf(err, "config.NewFromYAML: unmarshaling filename %s", filename)
+		}
+	}
+	// package paths, i.e. ./models, are relative to the config file
+	if c.Package, err = ResolvePackage(filepath.Dir(filename), c.Package); err != nil {
+		return nil, errors.Wrapf(err, "config.NewFromYAML: resolving package of %s", filename)
+	}
+	for _, t := range c.Types {
+		if t.Package, err = ResolvePackage(filepath.Dir(filename), t.Package); err != nil {
+			return nil, errors.Wrapf(err, "config.NewFromYAML: resolving package of type %s of %s", t.Name, filename)
+		}
+	}
+	for _, from := range []struct {
+		setting, filename string
+		importer          Importer
+	}{
+		{"from_openapi", c.FromOpenAPI, r.OpenAPI},
+		{"from_gqlgen", c.FromGQLGen, r.GQLGen},
+	} {
+		if from.filename == "" {
+			continue
+		}
+		if from.importer == nil {
+			return nil, errors.Errorf("config.NewFromYAML: %s of %s isn't supported", from.setting, filename)
+		}
+		name := from.filename
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(filename), name)
+		}
+		types, err := from.importer(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config.NewFromYAML: importing types from %s", from.filename)
+		}
+		c.Types = MergeTypes(c.Package, c.Types, types)
+	}
+	for _, include := range c.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(filename), include)
+		}
+		fragment, err := r.newFromYAML(include, including)
+		if err != nil {
+			return nil, err
+		}
+		defined := qualifiedNames(c.Package, c.Types)
+		for _, t := range fragment.Types {
+			// settings of the fragment apply to its own types only
+			fragment.inherit(t)
+			if _, ok := defined[qualifiedName(c.Package, t)]; ok {
+				return nil, errors.Errorf("config.NewFromYAML: type %s of %s is already defined in %s",
+					t.Name, include, filename)
+			}
+			c.Types = append(c.Types, t)
+		}
+	}
+	return &c, nil
+}
+
+// Merge overrides the settings of the configuration with the ones set in o and replaces the types with
+// the types of o of the same name and package, the rest of the types of o is appended. Types keep the package
+// of the configuration that defines them.
+func (c *Config) Merge(o *Config) {
+	if o.Version > c.Version {
+		c.Version = o.Version
+	}
+	if o.DecodingStrategy != "" {
+		c.DecodingStrategy = o.DecodingStrategy
+	}
+	if o.MarkerMethod != "" {
+		c.MarkerMethod = o.MarkerMethod
+	}
+	if o.Output != nil && o.Output.Filename != "" {
+		c.Output = o.Output
+	}
+	if o.Package != "" {
+		// types without package keep the package of the configuration that defines them
+		if o.Package != c.Package {
+			c.pinPackage()
+		}
+		c.Package = o.Package
+	}
+	if len(o.Targets) > 0 {
+		c.Targets = o.Targets
+	}
+	c.Types = OverrideTypes(c.Package, c.Types, o.Types)
+}
+
+// pinPackage sets the package of the types without one to the package of the configuration, if it has one.
+func (c *Config) pinPackage() {
+	if c.Package == "" {
+		return
+	}
+	for _, t := range c.Types {
+		if t.Package == "" {
+			t.Package = c.Package
+		}
+	}
+}
+
+// MergeTypes appends types that are not yet defined to the list, definitions of the list take precedence.
+// Types are identified by the name and the package, pkg is the package of the types without one.
+func MergeTypes(pkg string, list TypesList, types TypesList) TypesList {
+	defined := qualifiedNames(pkg, list)
+	for _, t := range types {
+		if _, ok := defined[qualifiedName(pkg, t)]; !ok {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// OverrideTypes replaces definitions of the list with the types of the same name and package, keeping their
+// order, and appends the rest of the types. pkg is the package of the types without one.
+func OverrideTypes(pkg string, list TypesList, types TypesList) TypesList {
+	for _, t := range types {
+		if i := slices.IndexFunc(list, func(def *TypeDefinition) bool {
+			return qualifiedName(pkg, def) == qualifiedName(pkg, t)
+		}); i >= 0 {
+			list[i] = t
+		} else {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// qualifiedNames returns types of the list by their qualified names, see qualifiedName.
+func qualifiedNames(pkg string, list TypesList) map[string]*TypeDefinition {
+	res := make(map[string]*TypeDefinition, len(list))
+	for _, t := range list {
+		res[qualifiedName(pkg, t)] = t
+	}
+	return res
+}
+
+// qualifiedName returns the name of the type qualified by its package, pkg is the package of the type without one.
+func qualifiedName(pkg string, t *TypeDefinition) string {
+	if t.Package != "" {
+		pkg = t.Package
+	}
+	return pkg + "." + t.Name
+}
+
+// inherit sets the settings the type definition doesn't override to the ones of the configuration.
+func (c *Config) inherit(t *TypeDefinition) {
+	if t.Package == "" {
+		t.Package = c.Package
+	}
+	if t.MarkerMethod == "" {
+		t.MarkerMethod = c.MarkerMethod
+	}
+	if t.Output == nil || t.Output.Filename == "" {
+		t.Output = c.Output
+	}
+	if t.DecodingStrategy == "" && len(t.Discriminator.Mapping) == 0 {
+		t.DecodingStrategy = c.DecodingStrategy
+	}
+	if len(t.Targets) == 0 {
+		t.Targets = c.Targets
+	}
+}
+
+// SetDefaults sets the marker method, decoding strategy and output the configuration doesn't set to
+// DefaultMarkerMethod, strict decoding and DefaultOutputFile.
+func (c *Config) SetDefaults() {
+	if c.MarkerMethod == "" {
+		c.MarkerMethod = DefaultMarkerMethod
+	}
+	if c.DecodingStrategy == "" {
+		c.DecodingStrategy = DecodingStrategyStrict
+	}
+	if c.Output == nil || c.Output.Filename == "" {
+		c.Output = &OutputConfig{Filename: DefaultOutputFile}
+	}
+}
+
+// Normalize propagates parent configuration to the type definitions that don't override it,
+// validates decoding strategies and expands marker-method templates, i.e. Is{{ .Name }}.
+func (c *Config) Normalize() error {
+	for _, t := range c.Types {
+		c.inherit(t)
+		// validate decoding strategy inputs
+		if t.DecodingStrategy == DecodingStrategyStrict && len(t.Discriminator.Mapping) > 0 {
+			return fmt.Errorf("type %s: can't have discriminator mapping & strict decoding", t.Name)
+		}
+		if t.DecodingStrategy == DecodingStrategyDiscriminator && len(t.Discriminator.Mapping) == 0 {
+			return fmt.Errorf("type %s: can't have discriminator decoding and empty mapping", t.Name)
+		}
+		if t.DecodingStrategy == "" && len(t.Discriminator.Mapping) > 0 {
+			t.DecodingStrategy = DecodingStrategyDiscriminator
+		}
+		if ds := t.DecodingStrategy; !ds.IsValid() {
+			return fmt.Errorf("type %s: not a valid decoding-strategy %s", t.Name, ds)
+		}
+		if t.Registry && !t.DecodingStrategy.IsDiscriminator() {
+			return fmt.Errorf("type %s: can't have registry without discriminator decoding", t.Name)
+		}
+		for _, target := range t.Targets {
+			if !target.Kind.IsValid() {
+				return fmt.Errorf("type %s: not a valid target kind %s", t.Name, target.Kind)
+			}
+			if target.Kind.IsGoSource() && !t.DecodingStrategy.IsDiscriminator() {
+				return fmt.Errorf("type %s: can't have %s target without discriminator decoding", t.Name, target.Kind)
+			}
+		}
+	}
+
+	for _, def := range c.Types {
+		m, err := expandTemplate(def.MarkerMethod, def.Name)
+		if err != nil {
+			return errors.Wrap(err, "expanding marker-method template")
+		}
+		def.MarkerMethod = m
+
+		// targets might be shared with the parent configuration
+		var targets []*TargetConfig
+		for _, target := range def.Targets {
+			filename, err := expandTemplate(target.Filename, def.Name)
+			if err != nil {
+				return errors.Wrapf(err, "expanding %s target filename template", target.Kind)
+			}
+			targets = append(targets, &TargetConfig{Kind: target.Kind, Filename: filename, Options: target.Options})
+		}
+		def.Targets = targets
+	}
+	return nil
+}
+
+// expandTemplate executes s as a template with the type name, i.e. Is{{ .Name }}.
+func expandTemplate(s, name string) (string, error) {
+	rx := regexp.MustCompile(`{{\s?\.(\w+)\s?}}`)
+	if !rx.MatchString(s) {
+		return s, nil
+	}
+	parse, err := template.New("").Parse(s)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing template")
+	}
+	var b bytes.Buffer
+	if err = parse.Execute(&b, map[string]string{"Name": name}); err != nil {
+		return "", errors.Wrap(err, "executing template")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/eugenenosenko/gopoly/jsonschema"
+)
+
+//go:generate go run .. schema -o ../config-json-schema.json
+
+// required keys of the configuration structs.
+var required = map[reflect.Type][]string{
+	reflect.TypeOf(TypeDefinition{}): {"name"},
+	reflect.TypeOf(TargetConfig{}):   {"kind", "filename"},
+	reflect.TypeOf(OutputConfig{}):   {"filename"},
+}
+
+// JSONSchema returns JSON schema of the configuration file built from the yaml and doc tags of Config and
+// the types it refers to, so that it accepts exactly the keys Validate does.
+func JSONSchema() *jsonschema.Schema {
+	defs := make(map[string]*jsonschema.Schema, 0)
+	res := objectSchema(reflect.TypeOf(Config{}), defs)
+	res.Schema = jsonschema.Draft
+	res.Title = "gopoly configuration"
+	res.Defs = defs
+
+	version, _ := res.Properties.Lookup("version")
+	for v := 1; v <= Version; v++ {
+		version.Schema.Enum = append(version.Schema.Enum, v)
+	}
+	return res
+}
+
+func objectSchema(typ reflect.Type, defs map[string]*jsonschema.Schema) *jsonschema.Schema {
+	res := &jsonschema.Schema{
+		Type:                 "object",
+		Required:             required[typ],
+		AdditionalProperties: jsonschema.Never(),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		s := typeSchema(f.Type, defs)
+		s.Description = f.Tag.Get("doc")
+		res.Properties = append(res.Properties, &jsonschema.Property{Name: name, Schema: s})
+	}
+	return res
+}
+
+func typeSchema(typ reflect.Type, defs map[string]*jsonschema.Schema) *jsonschema.Schema {
+	switch typ {
+	case reflect.TypeOf(DecodingStrategy("")):
+		return define(typ, defs, func() *jsonschema.Schema {
+			s := &jsonschema.Schema{Type: "string"}
+			for _, v := range DecodingStrategies {
+				s.Enum = append(s.Enum, v)
+			}
+			return s
+		})
+	case reflect.TypeOf(TargetKind("")):
+		return define(typ, defs, func() *jsonschema.Schema {
+			s := &jsonschema.Schema{Type: "string"}
+			for _, v := range TargetKinds {
+				s.Enum = append(s.Enum, v)
+			}
+			return s
+		})
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return typeSchema(typ.Elem(), defs)
+	case reflect.Struct:
+		return define(typ, defs, func() *jsonschema.Schema {
+			return objectSchema(typ, defs)
+		})
+	case reflect.Slice:
+		return &jsonschema.Schema{Type: "array", Items: typeSchema(typ.Elem(), defs)}
+	case reflect.Map:
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: typeSchema(typ.Elem(), defs)}
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case reflect.Int:
+		return &jsonschema.Schema{Type: "integer"}
+	default:
+		return &jsonschema.Schema{Type: "string"}
+	}
+}
+
+// define puts the schema of the named type into the definitions and returns reference to it.
+func define(typ reflect.Type, defs map[string]*jsonschema.Schema, fn func() *jsonschema.Schema) *jsonschema.Schema {
+	if _, ok := defs[typ.Name()]; !ok {
+		defs[typ.Name()] = nil // guards recursive types
+		defs[typ.Name()] = fn()
+	}
+	return &jsonschema.Schema{Ref: jsonschema.DefsPrefix + typ.Name()}
+}
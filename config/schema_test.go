@@ -0,0 +1,37 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	t.Run("should match config-json-schema.json, run go generate ./config to update it", func(t *testing.T) {
+		want, err := os.ReadFile("../config-json-schema.json")
+		require.NoError(t, err)
+
+		got, err := json.MarshalIndent(JSONSchema(), "", "  ")
+		require.NoError(t, err)
+		require.JSONEq(t, string(want), string(got))
+	})
+
+	t.Run("should describe every key accepted by the configuration", func(t *testing.T) {
+		schema := JSONSchema()
+		for _, p := range schema.Properties {
+			require.NotEmpty(t, p.Schema.Description, p.Name)
+		}
+		for name, def := range schema.Defs {
+			for _, p := range def.Properties {
+				require.NotEmpty(t, p.Schema.Description, name+"."+p.Name)
+			}
+		}
+		typ, ok := schema.Defs["TypeDefinition"]
+		require.True(t, ok)
+		require.Equal(t, []string{"name"}, typ.Required)
+		_, ok = typ.Properties.Lookup("variants")
+		require.True(t, ok)
+	})
+}
@@ -0,0 +1,11 @@
+package events
+
+type Event interface {
+	IsEvent()
+}
+
+type CreatedEvent struct {
+	Type string `json:"type"`
+}
+
+func (CreatedEvent) IsEvent() {}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a problem of the configuration at its position in the YAML file.
+type Problem struct {
+	Pos     token.Position
+	Message string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Pos, p.Message)
+}
+
+// Problems of the configuration, sorted by their position.
+type Problems []*Problem
+
+func (pp Problems) Error() string {
+	lines := make([]string, 0, len(pp))
+	for _, p := range pp {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var _ error = Problems(nil)
+
+// Validate reports all the problems of the configuration document read from filename: unknown and missing keys,
+// invalid decoding strategies and target kinds, duplicate type names, discriminator mappings that don't fit the decoding
+// strategy or refer to types that aren't variants and versions newer than Version. Missing decoding strategy is
+// considered to be strict.
+func Validate(filename string, doc *yaml.Node) Problems {
+	v := &validator{filename: filename}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind == 0 || doc.Kind == yaml.ScalarNode && doc.Tag == "!!null" {
+		return nil
+	}
+	v.config(doc)
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i].Pos, v.problems[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems
+}
+
+type validator struct {
+	filename string
+	problems Problems
+}
+
+func (v *validator) report(n *yaml.Node, format string, args ...any) {
+	v.problems = append(v.problems, &Problem{
+		Pos:     token.Position{Filename: v.filename, Line: n.Line, Column: n.Column},
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) config(n *yaml.Node) {
+	if !v.mapping(n, "config", reflect.TypeOf(Config{})) {
+		return
+	}
+
+	if version := lookup(n, "version"); version != nil {
+		if n, err := strconv.Atoi(version.Value); err != nil || n < 1 || n > Version {
+			v.report(version, "unsupported config version %s, latest supported version is %d", version.Value, Version)
+		}
+	}
+
+	strategy := DecodingStrategyStrict
+	if s := lookup(n, "decoding_strategy"); s != nil && s.Value != "" {
+		strategy = DecodingStrategy(s.Value)
+		if !strategy.IsValid() {
+			v.report(s, "not a valid decoding-strategy %s", s.Value)
+		}
+	}
+	v.output(lookup(n, "output"), "config")
+	if include := lookup(n, "include"); include != nil && include.Kind != yaml.SequenceNode {
+		v.report(include, "include must be a list")
+	}
+	targets := lookup(n, "targets")
+	v.targets(targets, "config")
+
+	types := lookup(n, "types")
+	if types == nil {
+		return
+	}
+	if types.Kind != yaml.SequenceNode {
+		v.report(types, "types must be a list")
+		return
+	}
+	var pkg string
+	if p := lookup(n, "package"); p != nil {
+		pkg = p.Value
+	}
+	defined := make(map[string]*yaml.Node, len(types.Content))
+	for _, t := range types.Content {
+		v.typeDefinition(t, strategy, targets, pkg, defined)
+	}
+}
+
+func (v *validator) typeDefinition(
+	n *yaml.Node,
+	parent DecodingStrategy,
+	parentTargets *yaml.Node,
+	parentPkg string,
+	defined map[string]*yaml.Node,
+) {
+	where := fmt.Sprintf("type at line %d", n.Line)
+	nn := lookup(n, "name")
+	if nn != nil && nn.Value != "" {
+		where = "type " + nn.Value
+	}
+	if !v.mapping(n, where, reflect.TypeOf(TypeDefinition{})) {
+		return
+	}
+	// types of the same name are duplicates only within the package
+	pkg := parentPkg
+	if p := lookup(n, "package"); p != nil && p.Value != "" {
+		pkg = p.Value
+	}
+	if nn == nil || nn.Value == "" {
+		v.report(n, "type without name")
+	} else if first, ok := defined[pkg+"."+nn.Value]; ok {
+		v.report(nn, "duplicate type %s, first defined at line %d", nn.Value, first.Line)
+	} else {
+		defined[pkg+"."+nn.Value] = nn
+	}
+
+	variants := make(map[string]struct{}, 0)
+	if vv := lookup(n, "variants"); vv != nil && vv.Kind == yaml.SequenceNode {
+		for _, variant := range vv.Content {
+			variants[variant.Value] = struct{}{}
+		}
+	}
+
+	var mapping *yaml.Node
+	discriminator := lookup(n, "discriminator")
+	if discriminator != nil && v.mapping(discriminator, where+" discriminator", reflect.TypeOf(DiscriminatorDefinition{})) {
+		mapping = lookup(discriminator, "mapping")
+		if mapping != nil && mapping.Kind != yaml.MappingNode {
+			v.report(mapping, "%s: discriminator mapping must be a map", where)
+			mapping = nil
+		}
+	}
+	if mapping != nil && len(variants) > 0 {
+		for i := 1; i < len(mapping.Content); i += 2 {
+			if value := mapping.Content[i]; !contains(variants, value.Value) {
+				v.report(value, "%s: discriminator mapping value %s is not a variant", where, value.Value)
+			}
+		}
+	}
+	hasMapping := mapping != nil && len(mapping.Content) > 0
+
+	strategy := parent
+	s := lookup(n, "decoding_strategy")
+	switch {
+	case s != nil && s.Value != "":
+		strategy = DecodingStrategy(s.Value)
+		if !strategy.IsValid() {
+			v.report(s, "%s: not a valid decoding-strategy %s", where, s.Value)
+			return
+		}
+		if strategy.IsStrict() && hasMapping {
+			v.report(s, "%s: can't have discriminator mapping & strict decoding", where)
+		}
+		if strategy.IsDiscriminator() && !hasMapping {
+			v.report(s, "%s: can't have discriminator decoding and empty mapping", where)
+		}
+	case hasMapping:
+		strategy = DecodingStrategyDiscriminator
+	case strategy.IsDiscriminator():
+		v.report(n, "%s: can't have discriminator decoding and empty mapping", where)
+	}
+
+	if r := lookup(n, "registry"); r != nil && r.Value == "true" && !strategy.IsDiscriminator() {
+		v.report(r, "%s: can't have registry without discriminator decoding", where)
+	}
+	v.output(lookup(n, "output"), where)
+	targets := lookup(n, "targets")
+	v.targets(targets, where)
+	if targets == nil {
+		targets = parentTargets
+	}
+	if strategy.IsDiscriminator() || targets == nil || targets.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, t := range targets.Content {
+		if k := lookup(t, "kind"); k != nil && TargetKind(k.Value).IsGoSource() {
+			v.report(k, "%s: can't have %s target without discriminator decoding", where, k.Value)
+		}
+	}
+}
+
+func (v *validator) targets(n *yaml.Node, where string) {
+	if n == nil {
+		return
+	}
+	if n.Kind != yaml.SequenceNode {
+		v.report(n, "%s: targets must be a list", where)
+		return
+	}
+	for _, t := range n.Content {
+		if !v.mapping(t, where+" target", reflect.TypeOf(TargetConfig{})) {
+			continue
+		}
+		if k := lookup(t, "kind"); k == nil {
+			v.report(t, "%s: target without kind", where)
+		} else if !TargetKind(k.Value).IsValid() {
+			v.report(k, "%s: not a valid target kind %s", where, k.Value)
+		}
+		if f := lookup(t, "filename"); f == nil || f.Value == "" {
+			v.report(t, "%s: target without filename", where)
+		}
+	}
+}
+
+// output reports output n, when it's set, that isn't a mapping with the filename.
+func (v *validator) output(n *yaml.Node, where string) {
+	if n == nil || !v.mapping(n, where+" output", reflect.TypeOf(OutputConfig{})) {
+		return
+	}
+	if f := lookup(n, "filename"); f == nil || f.Value == "" {
+		v.report(n, "%s: output without filename", where)
+	}
+}
+
+// mapping reports whether n is a mapping and reports its keys that aren't yaml keys of the struct type.
+func (v *validator) mapping(n *yaml.Node, where string, typ reflect.Type) bool {
+	if n.Kind != yaml.MappingNode {
+		v.report(n, "%s must be a map", where)
+		return false
+	}
+	known := yamlKeys(typ)
+	for i := 0; i < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if !contains(known, key.Value) {
+			v.report(key, "unknown key %s in %s", key.Value, where)
+		}
+	}
+	return true
+}
+
+func yamlKeys(typ reflect.Type) map[string]struct{} {
+	res := make(map[string]struct{}, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			res[name] = struct{}{}
+		}
+	}
+	return res
+}
+
+// lookup returns the value of the key in the mapping node, nil when it's not present.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func contains(set map[string]struct{}, s string) bool {
+	_, ok := set[s]
+	return ok
+}
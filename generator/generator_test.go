@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -20,49 +21,132 @@ func (d *dummyCreator) Write(b []byte) (n int, err error)        { return d.buf.
 func (d *dummyCreator) Provide(_ string) (io.WriteCloser, error) { return d, nil }
 func (d *dummyCreator) Close() error                             { return nil }
 
+const modelsPkg = "github.com/eugenenosenko/gopoly/internal/models"
+
+// advert returns discriminator decoded Advert with the variants built by variants.
+func advert(variants func(i *code.Interface) map[string]*code.Variant) *codegen.Type {
+	i := &code.Interface{Name: "Advert", MarkerMethod: "IsAdvert", Pkg: modelsPkg}
+	vv := variants(i)
+	for _, v := range vv {
+		i.Variants = append(i.Variants, v)
+	}
+	sort.Slice(i.Variants, func(a, b int) bool {
+		return i.Variants[a].Name < i.Variants[b].Name
+	})
+	return &codegen.Type{
+		Name:               "Advert",
+		Variants:           vv,
+		DecodingStrategy:   config.DecodingStrategyDiscriminator.String(),
+		DiscriminatorField: "type",
+	}
+}
+
+// sellVariant returns SellAdvert with the polymorphic field of the interface.
+func sellVariant(i *code.Interface) map[string]*code.Variant {
+	return map[string]*code.Variant{"SELL": {Name: "SellAdvert", Fields: code.PolyFieldList{
+		{
+			Name:      "Runner",
+			Tags:      "`json:\"sell\"`",
+			Interface: i,
+			Kind:      code.KindScalar,
+		},
+	}, Interface: i}}
+}
+
+// sellAndRentVariants returns SellAdvert and RentAdvert without fields.
+func sellAndRentVariants(i *code.Interface) map[string]*code.Variant {
+	return map[string]*code.Variant{
+		"SELL": {Name: "SellAdvert", Interface: i},
+		"RENT": {Name: "RentAdvert", Interface: i},
+	}
+}
+
 func TestGenerator(t *testing.T) {
-	t.Run("should correctly generate code for provided configuration", func(t *testing.T) {
-		var b bytes.Buffer
-		gen, err := NewTemplateGenerator(&Config{
-			Provider: &dummyCreator{&b},
-			Logf:     func(_ string, _ ...any) {},
-		})
-		require.NoError(t, err)
+	tests := []struct {
+		name      string
+		template  string
+		golden    string
+		pkg       string
+		variants  func(i *code.Interface) map[string]*code.Variant
+		configure func(t *codegen.Type)
+		options   map[string]string
+	}{
+		{
+			name:     "should correctly generate code for provided configuration",
+			template: templates.DefaultJSONTemplate(),
+			golden:   "testdata/output.golden",
+			pkg:      modelsPkg,
+			variants: sellVariant,
+		},
+		{
+			name:      "should correctly generate registry backed code for provided configuration",
+			template:  templates.DefaultJSONTemplate(),
+			golden:    "testdata/registry.golden",
+			pkg:       "models",
+			variants:  sellAndRentVariants,
+			configure: func(t *codegen.Type) { t.Registry = true },
+		},
+		{
+			name:      "should correctly generate SQL column type for provided configuration",
+			template:  templates.DefaultJSONTemplate(),
+			golden:    "testdata/column.golden",
+			pkg:       "models",
+			variants:  sellAndRentVariants,
+			configure: func(t *codegen.Type) { t.SQLColumn = true },
+		},
+		{
+			name:     "should correctly generate MessagePack decoders for provided configuration",
+			template: templates.MsgPackTemplate(),
+			golden:   "testdata/msgpack.golden",
+			pkg:      modelsPkg,
+			variants: sellVariant,
+		},
+		{
+			name:     "should correctly generate CBOR decoders for provided configuration",
+			template: templates.CBORTemplate(),
+			golden:   "testdata/cbor.golden",
+			pkg:      modelsPkg,
+			variants: sellVariant,
+		},
+		{
+			name:     "should correctly generate BSON decoders for provided configuration",
+			template: templates.BSONTemplate(),
+			golden:   "testdata/bson.golden",
+			pkg:      modelsPkg,
+			variants: sellVariant,
+		},
+		{
+			name:     "should correctly generate XML decoders selecting variants by xsi:type",
+			template: templates.XMLTemplate(),
+			golden:   "testdata/xml.golden",
+			pkg:      modelsPkg,
+			variants: sellVariant,
+			options:  map[string]string{config.XMLOptionAttribute: "xsi:type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			gen, err := NewTemplateGenerator(&Config{
+				Provider: &dummyCreator{&b},
+				Logf:     func(_ string, _ ...any) {},
+			})
+			require.NoError(t, err)
 
-		i := &code.Interface{
-			Name:         "Advert",
-			MarkerMethod: "IsAdvert",
-			Pkg:          "github.com/eugenenosenko/gopoly/internal/models",
-		}
-		sell := &code.Variant{Name: "SellAdvert", Fields: code.PolyFieldList{
-			{
-				Name:      "Runner",
-				Tags:      "`json:\"sell\"`",
-				Interface: i,
-				Kind:      code.KindScalar,
-			},
-		}, Interface: i}
-		i.Variants = code.VariantList{sell}
+			typ := advert(tt.variants)
+			if tt.configure != nil {
+				tt.configure(typ)
+			}
+			err = gen.Generate(&codegen.Task{
+				Filename: "_",
+				Template: tt.template,
+				Input:    &codegen.Input{Package: tt.pkg, Types: []*codegen.Type{typ}, Options: tt.options},
+			})
+			require.NoError(t, err)
 
-		err = gen.Generate(&codegen.Task{
-			Filename: "_",
-			Template: templates.DefaultJSONTemplate(),
-			Input: &codegen.Input{
-				Package: "github.com/eugenenosenko/gopoly/internal/models",
-				Types: []*codegen.Type{
-					{
-						Name:               "Advert",
-						Variants:           map[string]*code.Variant{"SELL": sell},
-						DecodingStrategy:   config.DecodingStrategyDiscriminator.String(),
-						DiscriminatorField: "type",
-					},
-				},
-			},
+			data, err := os.ReadFile(tt.golden)
+			require.NoError(t, err)
+			require.Equal(t, string(data), b.String())
 		})
-		require.NoError(t, err)
-
-		data, err := os.ReadFile("testdata/output.golden")
-		require.NoError(t, err)
-		require.Equal(t, string(data), b.String())
-	})
+	}
 }
@@ -3,18 +3,27 @@ module github.com/eugenenosenko/gopoly
 go 1.19
 
 require (
+	github.com/bufbuild/protocompile v0.6.0
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/pkg/errors v0.9.1
-	github.com/stretchr/testify v1.8.0
+	github.com/stretchr/testify v1.8.4
+	github.com/vektah/gqlparser/v2 v2.5.11
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	go.mongodb.org/mongo-driver v1.17.6
 	go.uber.org/multierr v1.8.0
 	golang.org/x/exp v0.0.0-20221114191408-850992195362
 	golang.org/x/tools v0.3.0
+	google.golang.org/protobuf v1.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.uber.org/atomic v1.10.0 // indirect
 	golang.org/x/mod v0.7.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.2.0 // indirect
 )
@@ -0,0 +1,244 @@
+// Package gqlgen reads gqlgen.yml and the GraphQL schema it refers to and returns the union and interface
+// types together with their members, as generated by gqlgen.
+//
+// gqlgen generates a Go interface with the Is<Name> marker method for every union and interface of the schema
+// and implements the marker method on every member. Members are identified in the payload by the __typename field.
+package gqlgen
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/internal/xmod"
+	"github.com/eugenenosenko/gopoly/internal/xslices"
+)
+
+// TypenameField is the discriminator field of the GraphQL payload.
+const TypenameField = "__typename"
+
+// Project is the gqlgen project described by the gqlgen.yml.
+type Project struct {
+	// Package is the import path of the package containing the generated models.
+	Package string
+	Unions  []*Union
+}
+
+// Union is a union or an interface of the GraphQL schema.
+type Union struct {
+	Name string
+	// Package is the import path of the package of the Go interface, either the package of the generated models
+	// or the package of the model the union is bound to.
+	Package string
+	// Mapping of the __typename values to the Go type names of the members.
+	Mapping map[string]string
+}
+
+// Model is the Go type a GraphQL type is bound to with the models of the gqlgen.yml.
+type Model struct {
+	// Package is the import path of the package of the type.
+	Package string
+	Name    string
+}
+
+type gqlgenConfig struct {
+	Schema stringList `yaml:"schema"`
+	Model  struct {
+		Filename string `yaml:"filename"`
+		Package  string `yaml:"package"`
+	} `yaml:"model"`
+	Models map[string]struct {
+		Model stringList `yaml:"model"`
+	} `yaml:"models"`
+}
+
+// stringList is a YAML value that is either a string or a list of strings.
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = []string{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// Load reads gqlgen.yml and the schema files matching its schema patterns. Package of the models is resolved from
+// the model filename and the go.mod of the module gqlgen.yml is in.
+func Load(filename string) (*Project, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "gqlgen.Load: reading filename %s", filename)
+	}
+	var c gqlgenConfig
+	if err = yaml.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrapf(err, "gqlgen.Load: unmarshaling filename %s", filename)
+	}
+	if len(c.Schema) == 0 {
+		c.Schema = stringList{"schema.graphql"}
+	}
+	if c.Model.Filename == "" {
+		c.Model.Filename = "models_gen.go"
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, errors.Wrap(err, "gqlgen.Load: resolving config directory")
+	}
+	pkg, err := xmod.ImportPath(filepath.Join(dir, filepath.Dir(c.Model.Filename)))
+	if err != nil {
+		return nil, errors.Wrap(err, "gqlgen.Load: resolving models package")
+	}
+
+	var sources []*ast.Source
+	for _, pattern := range c.Schema {
+		files, err := glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "gqlgen.Load: matching schema pattern %s", pattern)
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "gqlgen.Load: reading schema %s", f)
+			}
+			sources = append(sources, &ast.Source{Name: f, Input: string(data)})
+		}
+	}
+
+	models := make(map[string]*Model, 0)
+	for name, m := range c.Models {
+		if len(m.Model) > 0 {
+			// gqlgen binds the type to the first of the models
+			ref := m.Model[0]
+			model := &Model{Name: ref}
+			if i := strings.LastIndex(ref, "."); i >= 0 {
+				model.Package, model.Name = ref[:i], ref[i+1:]
+			}
+			models[name] = model
+		}
+	}
+	unions, err := ParseSchema(pkg, models, sources...)
+	if err != nil {
+		return nil, errors.Wrap(err, "gqlgen.Load")
+	}
+	return &Project{Package: pkg, Unions: unions}, nil
+}
+
+// ImportTypes loads the gqlgen project and returns type definitions of its unions and interfaces, it's
+// the config.Importer of the from_gqlgen setting.
+func ImportTypes(filename string) (config.TypesList, error) {
+	p, err := Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Types(p), nil
+}
+
+// Types returns type definitions of the unions and interfaces of the gqlgen project. Types are
+// decoded by the __typename field and use Is<Name> marker methods emitted by gqlgen. Unions without
+// members are skipped.
+func Types(p *Project) config.TypesList {
+	res := make(config.TypesList, 0, len(p.Unions))
+	for _, u := range p.Unions {
+		if len(u.Mapping) == 0 {
+			continue
+		}
+		variants := maps.Keys(xslices.ToSet[[]string](maps.Values(u.Mapping)))
+		sort.Strings(variants)
+		res = append(res, &config.TypeDefinition{
+			Name:             u.Name,
+			Variants:         variants,
+			MarkerMethod:     "Is" + u.Name,
+			DecodingStrategy: config.DecodingStrategyDiscriminator,
+			Discriminator:    config.DiscriminatorDefinition{Field: TypenameField, Mapping: u.Mapping},
+			Package:          u.Package,
+		})
+	}
+	return res
+}
+
+// ParseSchema returns unions and interfaces of the GraphQL schema sorted by name. Types are placed into the pkg
+// and their names are converted to Go type names the way gqlgen does it, unless present in models, i.e. bound
+// to existing models in gqlgen.yml. Members of the union must be in the package of the union.
+func ParseSchema(pkg string, models map[string]*Model, sources ...*ast.Source) ([]*Union, error) {
+	doc, err := parser.ParseSchemas(sources...)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing GraphQL schema")
+	}
+	model := func(name string) *Model {
+		if m, ok := models[name]; ok {
+			return m
+		}
+		return &Model{Package: pkg, Name: TypeName(name)}
+	}
+
+	members := make(map[string][]string, 0)
+	abstract := make(map[string]struct{}, 0)
+	for _, d := range append(doc.Definitions, doc.Extensions...) {
+		switch d.Kind {
+		case ast.Union:
+			abstract[d.Name] = struct{}{}
+			members[d.Name] = append(members[d.Name], d.Types...)
+		case ast.Interface:
+			abstract[d.Name] = struct{}{}
+		case ast.Object:
+			for _, iface := range d.Interfaces {
+				members[iface] = append(members[iface], d.Name)
+			}
+		}
+	}
+
+	names := maps.Keys(abstract)
+	sort.Strings(names)
+	res := make([]*Union, 0, len(names))
+	for _, name := range names {
+		m := model(name)
+		u := &Union{Name: m.Name, Package: m.Package, Mapping: make(map[string]string, 0)}
+		for _, member := range members[name] {
+			mm := model(member)
+			if mm.Package != u.Package {
+				return nil, errors.Errorf("member %s of %s is bound to %s.%s outside of the package %s",
+					member, name, mm.Package, mm.Name, u.Package)
+			}
+			u.Mapping[member] = mm.Name
+		}
+		res = append(res, u)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// glob extends filepath.Glob with the ** pattern matching any number of directories, as gqlgen does.
+func glob(pattern string) ([]string, error) {
+	i := strings.Index(pattern, "**")
+	if i < 0 {
+		return filepath.Glob(pattern)
+	}
+	root, rest := filepath.Clean(pattern[:i]), strings.TrimPrefix(pattern[i+2:], string(filepath.Separator))
+	var res []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ok, _ := filepath.Match(rest, filepath.Base(path)); ok {
+			res = append(res, path)
+		}
+		return nil
+	})
+	return res, err
+}
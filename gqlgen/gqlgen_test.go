@@ -0,0 +1,117 @@
+package gqlgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("should read unions and interfaces of the gqlgen project", func(t *testing.T) {
+		p, err := Load("testdata/project/gqlgen.yml")
+		require.NoError(t, err)
+
+		const pkg = "github.com/username/example/graph/model"
+		require.Equal(t, &Project{
+			Package: pkg,
+			Unions: []*Union{
+				{Name: "Actor", Package: pkg, Mapping: map[string]string{"User": "User"}},
+				{Name: "Empty", Package: pkg, Mapping: map[string]string{}},
+				{Name: "Node", Package: pkg, Mapping: map[string]string{"User": "User", "Admin": "Administrator", "Post": "Post"}},
+				{Name: "SearchResult", Package: pkg, Mapping: map[string]string{"User": "User", "Post": "Post"}},
+			},
+		}, p)
+	})
+}
+
+func TestParseSchema(t *testing.T) {
+	t.Run("should convert names of the types to Go type names", func(t *testing.T) {
+		unions, err := ParseSchema("example/model", nil, &ast.Source{Input: `union search_result = user_post | apiKey`})
+		require.NoError(t, err)
+
+		require.Equal(t, []*Union{
+			{
+				Name:    "SearchResult",
+				Package: "example/model",
+				Mapping: map[string]string{"user_post": "UserPost", "apiKey": "APIKey"},
+			},
+		}, unions)
+	})
+
+	t.Run("should place unions bound to the models into their packages", func(t *testing.T) {
+		unions, err := ParseSchema("example/model", map[string]*Model{
+			"Node": {Package: "example/nodes", Name: "Node"},
+			"User": {Package: "example/nodes", Name: "Account"},
+		}, &ast.Source{Input: `
+interface Node { id: ID! }
+type User implements Node { id: ID! }
+`})
+		require.NoError(t, err)
+
+		require.Equal(t, []*Union{
+			{Name: "Node", Package: "example/nodes", Mapping: map[string]string{"User": "Account"}},
+		}, unions)
+	})
+
+	t.Run("should return error when member is bound outside of the package of the union", func(t *testing.T) {
+		_, err := ParseSchema("example/model", map[string]*Model{
+			"User": {Package: "example/users", Name: "User"},
+		}, &ast.Source{Input: `union Actor = User`})
+		require.EqualError(t, err, "member User of Actor is bound to example/users.User outside of the package example/model")
+	})
+
+	t.Run("should return error on invalid schema", func(t *testing.T) {
+		_, err := ParseSchema("example/model", nil, &ast.Source{Name: "schema.graphqls", Input: `union Actor =`})
+		require.ErrorContains(t, err, "parsing GraphQL schema")
+	})
+}
+
+func TestTypeName(t *testing.T) {
+	t.Run("should convert names the way gqlgen does", func(t *testing.T) {
+		for in, want := range map[string]string{
+			"user_event": "UserEvent",
+			"apiKey":     "APIKey",
+			"userId":     "UserID",
+			"Comment":    "Comment",
+			"html_page":  "HTMLPage",
+			"URLs":       "URLs",
+			"IDFoo":      "IDFoo",
+			"search":     "Search",
+		} {
+			require.Equal(t, want, TypeName(in), in)
+		}
+	})
+}
+
+func TestTypes(t *testing.T) {
+	t.Run("should build discriminator definitions decoded by __typename", func(t *testing.T) {
+		types := Types(&Project{
+			Package: "github.com/username/example/graph/model",
+			Unions: []*Union{
+				{Name: "Empty", Package: "github.com/username/example/graph/model", Mapping: map[string]string{}},
+				{
+					Name:    "Node",
+					Package: "github.com/username/example/users",
+					Mapping: map[string]string{"User": "User", "Admin": "Administrator"},
+				},
+			},
+		})
+
+		require.Equal(t, config.TypesList{
+			{
+				Name:             "Node",
+				Variants:         []string{"Administrator", "User"},
+				MarkerMethod:     "IsNode",
+				DecodingStrategy: config.DecodingStrategyDiscriminator,
+				Discriminator: config.DiscriminatorDefinition{
+					Field:   "__typename",
+					Mapping: map[string]string{"User": "User", "Admin": "Administrator"},
+				},
+				Package: "github.com/username/example/users",
+			},
+		}, types)
+	})
+}
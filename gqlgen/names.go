@@ -0,0 +1,145 @@
+package gqlgen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TypeName converts GraphQL type name to the Go type name following the rules of gqlgen templates.ToGo,
+// i.e. user_event -> UserEvent and apiKey -> APIKey.
+func TypeName(name string) string {
+	if name == "_" {
+		return "_"
+	}
+	runes := make([]rune, 0, len(name))
+	words(name, func(word string, initialism, hasInitialism bool) {
+		switch {
+		case initialism:
+			word = strings.ToUpper(word)
+		case !hasInitialism && (strings.ToUpper(word) == word || strings.ToLower(word) == word):
+			// FOO or foo -> Foo, FOo is kept
+			word = upperFirst(strings.ToLower(word))
+		}
+		runes = append(runes, []rune(word)...)
+	})
+	return string(runes)
+}
+
+// words splits the name into words on delimiters and lower to upper case transitions and calls fn with every word,
+// whether the word is a common initialism and whether it contains one, i.e. URLs.
+func words(name string, fn func(word string, initialism, hasInitialism bool)) {
+	runes := []rune(strings.TrimFunc(name, isDelimiter))
+	w, i := 0, 0
+	hasInitialism := false
+	for i+1 <= len(runes) {
+		eow := false
+		switch {
+		case i+1 == len(runes):
+			eow = true
+		case isDelimiter(runes[i+1]):
+			// shift the remainder forward over the run of delimiters
+			eow = true
+			n := 1
+			for i+n+1 < len(runes) && isDelimiter(runes[i+n+1]) {
+				n++
+			}
+			// leave one underscore between two digits
+			if i+n+1 < len(runes) && unicode.IsDigit(runes[i]) && unicode.IsDigit(runes[i+n+1]) {
+				n--
+			}
+			copy(runes[i+1:], runes[i+n+1:])
+			runes = runes[:len(runes)-n]
+		case unicode.IsLower(runes[i]) && !unicode.IsLower(runes[i+1]):
+			eow = true
+		}
+		i++
+
+		word := string(runes[w:i])
+		split := false
+		if !eow && commonInitialisms[word] && !unicode.IsLower(runes[i]) {
+			// split IDFoo into ID and Foo, but keep URLs
+			split = true
+		} else if !eow {
+			if commonInitialisms[word] {
+				hasInitialism = true
+			}
+			continue
+		}
+
+		initialism := false
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			// leading ID or IP followed by an upper case word isn't an initialism
+			if (upper == "ID" || upper == "IP") && w == 0 && split && len(runes) > 3 && unicode.IsUpper(runes[3]) {
+				continue
+			}
+			hasInitialism = true
+			initialism = true
+		}
+		fn(word, initialism, hasInitialism)
+		hasInitialism = false
+		w = i
+	}
+}
+
+func isDelimiter(c rune) bool {
+	return c == '-' || c == '_' || unicode.IsSpace(c)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// commonInitialisms are the initialisms gqlgen keeps upper case in the Go names.
+var commonInitialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"CSV":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ICMP":  true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"KVK":   true,
+	"LHS":   true,
+	"PDF":   true,
+	"PGP":   true,
+	"QPS":   true,
+	"QR":    true,
+	"RAM":   true,
+	"RHS":   true,
+	"RPC":   true,
+	"SLA":   true,
+	"SMTP":  true,
+	"SQL":   true,
+	"SSH":   true,
+	"SVG":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"URI":   true,
+	"URL":   true,
+	"UTF8":  true,
+	"UUID":  true,
+	"VM":    true,
+	"XML":   true,
+	"XMPP":  true,
+	"XSRF":  true,
+	"XSS":   true,
+}
@@ -0,0 +1,3 @@
+module github.com/username/example
+
+go 1.19
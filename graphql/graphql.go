@@ -0,0 +1,184 @@
+// Package graphql renders GraphQL SDL of the configured interfaces. Interface is declared as a union of its
+// variants and every variant, as well as the structs it references, as an object type built from the
+// JSON fields of the struct:
+//
+//	union UserEvent = UserCreatedEvent | UserDeletedEvent
+//
+//	type UserCreatedEvent {
+//	  type: String!
+//	  user: User!
+//	}
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/internal/decls"
+)
+
+const (
+	// scalarTime is declared for time.Time fields.
+	scalarTime = "Time"
+	// scalarInt64 is declared for integers that don't fit into the 32-bit signed Int of GraphQL.
+	scalarInt64 = "Int64"
+	// scalarJSON is declared for maps and the types that can't be described, i.e. from other packages.
+	scalarJSON = "JSON"
+)
+
+// Schema returns GraphQL SDL of the interfaces, their variants and the declarations they reference.
+// Returns error when declarations of different packages have the same name.
+func Schema(ifaces code.InterfaceList) (string, error) {
+	r := &renderer{decls: decls.New("graphql"), scalars: make(map[string]struct{}, 0)}
+	for _, iface := range ifaces {
+		r.union(iface)
+	}
+	if err := r.decls.Flush(); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	scalars := make([]string, 0, len(r.scalars))
+	for s := range r.scalars {
+		scalars = append(scalars, s)
+	}
+	sort.Strings(scalars)
+	for _, s := range scalars {
+		sb.WriteString(fmt.Sprintf("scalar %s\n", s))
+	}
+	if len(scalars) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(r.sb.String())
+	return sb.String(), nil
+}
+
+type renderer struct {
+	sb      strings.Builder
+	decls   *decls.Set
+	scalars map[string]struct{}
+}
+
+func (r *renderer) separate() {
+	if r.sb.Len() > 0 {
+		r.sb.WriteString("\n")
+	}
+}
+
+func (r *renderer) union(iface *code.Interface) {
+	if !r.decls.Declare(iface.Pkg, iface.Name) {
+		return
+	}
+	variants := iface.MappedVariants()
+	names := make([]string, 0, len(variants))
+	for _, v := range variants {
+		names = append(names, v.Name)
+	}
+
+	if len(names) == 0 {
+		// union must have at least one member
+		r.decls.Fail(fmt.Errorf("graphql: %s has no variants", iface.Name))
+		return
+	}
+	r.separate()
+	r.sb.WriteString(fmt.Sprintf("union %s = %s\n", iface.Name, strings.Join(names, " | ")))
+	for _, v := range variants {
+		if r.decls.Declare(iface.Pkg, v.Name) {
+			r.object(v.Name, v.Struct)
+		}
+	}
+}
+
+func (r *renderer) object(name string, s *code.Struct) {
+	r.separate()
+	r.sb.WriteString(fmt.Sprintf("type %s {\n", name))
+	fields := s.JSONFields()
+	if len(fields) == 0 {
+		// object type must have at least one field
+		r.sb.WriteString("  _: Boolean\n")
+	}
+	for _, f := range fields {
+		t := r.typeRef(f.Field.Type)
+		if f.OmitEmpty {
+			t = strings.TrimSuffix(t, "!")
+		}
+		r.sb.WriteString(fmt.Sprintf("  %s: %s\n", fieldName(f.Name), t))
+	}
+	r.sb.WriteString("}\n")
+}
+
+// typeRef returns GraphQL type of the field, non-null unless it's a pointer.
+func (r *renderer) typeRef(ref *code.TypeRef) string {
+	if ref == nil {
+		return r.scalar(scalarJSON) + "!"
+	}
+	switch ref.Kind {
+	case code.RefPointer:
+		return strings.TrimSuffix(r.typeRef(ref.Elem), "!")
+	case code.RefSlice:
+		if ref.IsBytes() {
+			return "String!"
+		}
+		return fmt.Sprintf("[%s]!", r.typeRef(ref.Elem))
+	case code.RefIdent:
+		return r.ident(ref) + "!"
+	default:
+		return r.scalar(scalarJSON) + "!"
+	}
+}
+
+func (r *renderer) ident(ref *code.TypeRef) string {
+	switch {
+	case ref.Interface != nil:
+		iface := ref.Interface
+		r.decls.Defer(func() { r.union(iface) })
+		return iface.Name
+	case ref.Struct != nil:
+		st := ref.Struct
+		r.decls.Defer(func() {
+			if r.decls.Declare(st.Pkg, st.Name) {
+				r.object(st.Name, st)
+			}
+		})
+		return st.Name
+	case ref.Pkg == "time" && ref.Name == "Time":
+		return r.scalar(scalarTime)
+	case ref.Pkg == "time" && ref.Name == "Duration":
+		return r.scalar(scalarInt64)
+	}
+
+	// named basic types are described by their underlying types
+	switch ref.Basic() {
+	case "string":
+		return "String"
+	case "bool":
+		return "Boolean"
+	case "float32", "float64":
+		return "Float"
+	case "int", "int8", "int16", "int32", "uint8", "uint16", "byte", "rune":
+		return "Int"
+	case "int64", "uint", "uint32", "uint64", "uintptr":
+		return r.scalar(scalarInt64)
+	default:
+		return r.scalar(scalarJSON)
+	}
+}
+
+func (r *renderer) scalar(name string) string {
+	r.scalars[name] = struct{}{}
+	return name
+}
+
+// fieldName replaces characters that are not allowed in GraphQL names with underscores.
+func fieldName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		b[i] = '_'
+	}
+	return string(b)
+}
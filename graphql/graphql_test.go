@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+func TestSchema(t *testing.T) {
+	t.Run("should declare unions of the variants and object types from struct fields", func(t *testing.T) {
+		contact := &code.Interface{Name: "Contact", Pkg: "github.com/eugenenosenko/gopoly/models"}
+		contact.Variants = code.VariantList{
+			{Name: "EmailContact", Interface: contact, Struct: &code.Struct{
+				Name: "EmailContact",
+				Fields: code.FieldList{
+					{Name: "Email", Tags: "`json:\"e-mail\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				},
+			}},
+			{Name: "Unreachable", Interface: contact, Struct: &code.Struct{Name: "Unreachable"}},
+		}
+
+		address := &code.Struct{Name: "Address", Fields: code.FieldList{
+			{Name: "City", Tags: "`json:\"city\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+		}}
+		event := &code.Interface{
+			Name:          "UserEvent",
+			Pkg:           "github.com/eugenenosenko/gopoly/models",
+			Discriminator: &code.Discriminator{Field: "type", Mapping: map[string]string{"CREATED": "UserCreatedEvent", "DELETED": "UserDeletedEvent"}},
+		}
+		event.Variants = code.VariantList{
+			{Name: "UserDeletedEvent", Interface: event, Struct: &code.Struct{
+				Name: "UserDeletedEvent",
+				Fields: code.FieldList{
+					{Name: "Type", Tags: "`json:\"type\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+					{Name: "DeletedAt", Tags: "`json:\"deleted_at\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Time", Pkg: "time"}},
+				},
+			}},
+			{Name: "UserCreatedEvent", Interface: event, Struct: &code.Struct{
+				Name: "UserCreatedEvent",
+				Fields: code.FieldList{
+					{Name: "Type", Tags: "`json:\"type\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+					{Name: "Age", Tags: "`json:\"age,omitempty\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "int"}},
+					{Name: "Version", Tags: "`json:\"version\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "uint64"}},
+					{Name: "Score", Tags: "`json:\"score\"`", Type: &code.TypeRef{
+						Kind: code.RefPointer,
+						Elem: &code.TypeRef{Kind: code.RefIdent, Name: "float64"},
+					}},
+					{Name: "Contacts", Tags: "`json:\"contacts\"`", Type: &code.TypeRef{
+						Kind: code.RefSlice,
+						Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Contact", Interface: contact},
+					}},
+					{Name: "Address", Tags: "`json:\"address\"`", Type: &code.TypeRef{
+						Kind: code.RefPointer,
+						Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Address", Struct: address},
+					}},
+					{Name: "Labels", Tags: "`json:\"labels\"`", Type: &code.TypeRef{
+						Kind: code.RefMap,
+						Elem: &code.TypeRef{Kind: code.RefIdent, Name: "string"},
+					}},
+					{Name: "Status", Tags: "`json:\"status\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Status", Underlying: "string"}},
+				},
+			}},
+		}
+
+		actual, err := Schema(code.InterfaceList{event})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile("testdata/events.golden.graphql")
+		require.NoError(t, err)
+		require.Equal(t, string(data), actual)
+	})
+
+	t.Run("should fail when interface has no variants", func(t *testing.T) {
+		_, err := Schema(code.InterfaceList{{Name: "Empty", Pkg: "github.com/eugenenosenko/gopoly/models"}})
+		require.EqualError(t, err, "graphql: Empty has no variants")
+	})
+
+	t.Run("should fail when declarations of different packages have the same name", func(t *testing.T) {
+		order := &code.Interface{Name: "OrderEvent", Pkg: "github.com/eugenenosenko/gopoly/orders"}
+		order.Variants = code.VariantList{{Name: "Created", Interface: order, Struct: &code.Struct{Name: "Created"}}}
+		user := &code.Interface{Name: "UserEvent", Pkg: "github.com/eugenenosenko/gopoly/users"}
+		user.Variants = code.VariantList{{Name: "Created", Interface: user, Struct: &code.Struct{Name: "Created"}}}
+
+		_, err := Schema(code.InterfaceList{order, user})
+		require.EqualError(t, err, "graphql: github.com/eugenenosenko/gopoly/orders.Created and "+
+			"github.com/eugenenosenko/gopoly/users.Created are both declared as Created")
+	})
+}
@@ -0,0 +1,57 @@
+// Package decls keeps track of the declarations rendered by the schema targets. Declarations are named after
+// the bare names of the Go declarations, so declarations of different packages with the same name conflict.
+package decls
+
+import (
+	"fmt"
+)
+
+// Set of the rendered declarations and the queue of the declarations they reference.
+type Set struct {
+	prefix string
+	// owners maps names of the declarations to their package qualified names
+	owners map[string]string
+	queue  []func()
+	err    error
+}
+
+// New returns Set reporting errors with the prefix, i.e. the name of the target.
+func New(prefix string) *Set {
+	return &Set{prefix: prefix, owners: make(map[string]string, 0)}
+}
+
+// Declare returns false if the declaration with the name has been already declared. Declaration
+// of another package with the same name fails the rendering.
+func (s *Set) Declare(pkg, name string) bool {
+	owner := pkg + "." + name
+	if declared, ok := s.owners[name]; ok {
+		if declared != owner {
+			s.Fail(fmt.Errorf("%s: %s and %s are both declared as %s", s.prefix, declared, owner, name))
+		}
+		return false
+	}
+	s.owners[name] = owner
+	return true
+}
+
+// Defer queues rendering of the referenced declaration after the ones being rendered.
+func (s *Set) Defer(fn func()) {
+	s.queue = append(s.queue, fn)
+}
+
+// Fail records the error, only the first one is reported.
+func (s *Set) Fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Flush renders the queued declarations, including the ones they reference, and returns the first error.
+func (s *Set) Flush() error {
+	for len(s.queue) > 0 && s.err == nil {
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		next()
+	}
+	return s.err
+}
@@ -0,0 +1,36 @@
+package decls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	t.Run("should render queued declarations once", func(t *testing.T) {
+		s := New("test")
+		var rendered []string
+		var render func(name string)
+		render = func(name string) {
+			if !s.Declare("models", name) {
+				return
+			}
+			rendered = append(rendered, name)
+			s.Defer(func() { render("User") })
+		}
+		render("Event")
+
+		require.NoError(t, s.Flush())
+		require.Equal(t, []string{"Event", "User"}, rendered)
+	})
+
+	t.Run("should fail when declarations of different packages have the same name", func(t *testing.T) {
+		s := New("test")
+		require.True(t, s.Declare("models", "User"))
+		require.False(t, s.Declare("models", "User"))
+		require.NoError(t, s.Flush())
+
+		require.False(t, s.Declare("users", "User"))
+		require.EqualError(t, s.Flush(), "test: models.User and users.User are both declared as User")
+	})
+}
@@ -3,6 +3,7 @@ package xfs
 import (
 	"io"
 	"os"
+	"path/filepath"
 )
 
 type FileWriterProviderFunc func(name string) (*os.File, error)
@@ -11,6 +12,14 @@ func (f FileWriterProviderFunc) Provide(name string) (io.WriteCloser, error) {
 	return f(name)
 }
 
+// CreateFile creates the named file along with its missing parent directories.
+func CreateFile(name string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(name)
+}
+
 func FileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
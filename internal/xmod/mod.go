@@ -0,0 +1,69 @@
+// Package xmod resolves import paths of the directories based on the go.mod of their module and
+// directories of the packages loaded by the go command.
+package xmod
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// ImportPath returns import path of the directory based on the go.mod of the module it belongs to.
+func ImportPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			module := modulePath(string(data))
+			if module == "" {
+				return "", errors.Errorf("missing module directive in %s", filepath.Join(d, "go.mod"))
+			}
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		if filepath.Dir(d) == d {
+			return "", errors.Errorf("%s is not in a Go module", dir)
+		}
+	}
+}
+
+// IsRelative reports whether the package path is relative to a directory, i.e. ./models, rather than
+// an import path.
+func IsRelative(pkg string) bool {
+	return pkg == "." || pkg == ".." || strings.HasPrefix(pkg, "./") || strings.HasPrefix(pkg, "../")
+}
+
+func modulePath(gomod string) string {
+	for _, line := range strings.Split(gomod, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// PackageDir returns the directory of the package loaded with packages.NeedFiles, empty when package has
+// no files. Unlike go/build, the go command resolves packages of all the modules of the go.work workspace.
+func PackageDir(pkg *packages.Package) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
+		}
+	}
+	return ""
+}
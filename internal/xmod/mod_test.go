@@ -0,0 +1,34 @@
+package xmod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportPath(t *testing.T) {
+	t.Run("should resolve import path of the directory in the module", func(t *testing.T) {
+		root := t.TempDir()
+		dir := filepath.Join(root, "models", "events")
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.19\n"), 0o644))
+
+		pkg, err := ImportPath(dir)
+		require.NoError(t, err)
+		require.Equal(t, "example.com/app/models/events", pkg)
+
+		pkg, err = ImportPath(root)
+		require.NoError(t, err)
+		require.Equal(t, "example.com/app", pkg)
+	})
+
+	t.Run("should tell relative package paths from import paths", func(t *testing.T) {
+		require.True(t, IsRelative("./models"))
+		require.True(t, IsRelative("../models"))
+		require.True(t, IsRelative("."))
+		require.False(t, IsRelative("github.com/username/example/models"))
+		require.False(t, IsRelative(".models"))
+	})
+}
@@ -0,0 +1,309 @@
+// Package jsonschema builds JSON Schema (draft 2020-12) documents describing configured polymorphic
+// interfaces. Interface is described as oneOf of its variants, discriminated interfaces additionally
+// carry the discriminator keyword with the mapping of the tags to the variant schemas.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/internal/decls"
+)
+
+const (
+	// Draft is the JSON Schema dialect of the generated documents.
+	Draft = "https://json-schema.org/draft/2020-12/schema"
+
+	// DefsPrefix is the prefix of the references to the definitions of the JSON Schema document.
+	DefsPrefix = "#/$defs/"
+
+	// ComponentsPrefix is the prefix of the references to the schemas of the OpenAPI document.
+	ComponentsPrefix = "#/components/schemas/"
+)
+
+// Schema is a subset of JSON Schema keywords used to describe Go types.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           Properties         `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Discriminator        *Discriminator     `json:"discriminator,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// never is set for the schema that doesn't match anything, encoded as false.
+	never bool
+}
+
+// Never returns schema that doesn't match anything, i.e. additionalProperties of the closed objects.
+func Never() *Schema {
+	return &Schema{never: true}
+}
+
+// MarshalJSON encodes the schema, schema that doesn't match anything is encoded as false.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// Discriminator is the OpenAPI discriminator keyword, it's ignored by JSON Schema validators
+// but understood by most of the code generators.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+// Property of the object schema.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties of the object schema, encoded in the order of the struct fields.
+type Properties []*Property
+
+func (pp Properties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, p := range pp {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, err := json.Marshal(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteString(":")
+		b.Write(schema)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// Lookup returns property with the name.
+func (pp Properties) Lookup(name string) (*Property, bool) {
+	for _, p := range pp {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Document returns JSON Schema document describing the interface. Variants and other referenced
+// declarations are placed into the $defs of the document.
+func Document(iface *code.Interface) (*Schema, error) {
+	b := NewBuilder(DefsPrefix)
+	s := b.Interface(iface)
+	s.Schema = Draft
+	s.Title = iface.Name
+	defs, err := b.Definitions()
+	if err != nil {
+		return nil, err
+	}
+	s.Defs = defs
+	return s, nil
+}
+
+// Components returns schemas of the interfaces, their variants and other referenced declarations keyed by the name,
+// as expected by the components.schemas of the OpenAPI document.
+func Components(ifaces code.InterfaceList) (map[string]*Schema, error) {
+	b := NewBuilder(ComponentsPrefix)
+	for _, iface := range ifaces {
+		b.define(iface.Pkg, iface.Name, func() *Schema {
+			return b.Interface(iface)
+		})
+	}
+	return b.Definitions()
+}
+
+// Builder builds schemas of the interfaces collecting referenced declarations as definitions.
+type Builder struct {
+	prefix string
+	defs   map[string]*Schema
+	decls  *decls.Set
+}
+
+// NewBuilder returns Builder that references definitions with the prefix,
+// i.e. #/$defs/ or #/components/schemas/.
+func NewBuilder(prefix string) *Builder {
+	return &Builder{prefix: prefix, defs: make(map[string]*Schema, 0), decls: decls.New("jsonschema")}
+}
+
+// Definitions returns schemas of the declarations referenced so far, keyed by the name. Returns error when
+// declarations of different packages have the same name, since definitions are keyed by the bare name.
+func (b *Builder) Definitions() (map[string]*Schema, error) {
+	if err := b.decls.Flush(); err != nil {
+		return nil, err
+	}
+	return b.defs, nil
+}
+
+// Interface returns oneOf schema of the interface variants, variant schemas are added to the definitions.
+func (b *Builder) Interface(iface *code.Interface) *Schema {
+	res := &Schema{}
+	if d := iface.Discriminator; d != nil {
+		res.Discriminator = &Discriminator{PropertyName: d.Field, Mapping: make(map[string]string, 0)}
+		for tag, name := range d.Mapping {
+			res.Discriminator.Mapping[tag] = b.prefix + name
+		}
+	}
+	for _, v := range iface.MappedVariants() {
+		res.OneOf = append(res.OneOf, b.variant(iface, v))
+	}
+	return res
+}
+
+func (b *Builder) variant(iface *code.Interface, v *code.Variant) *Schema {
+	return b.define(iface.Pkg, v.Name, func() *Schema {
+		s := b.object(v.Struct)
+		if iface.Discriminator == nil {
+			// strict decoding disallows unknown fields
+			s.AdditionalProperties = Never()
+			return s
+		}
+
+		tags := iface.Discriminator.Tags(v.Name)
+		field := iface.Discriminator.Field
+		tag := &Schema{Type: "string"}
+		if len(tags) == 1 {
+			tag.Const = tags[0]
+		} else {
+			for _, t := range tags {
+				tag.Enum = append(tag.Enum, t)
+			}
+		}
+		if p, ok := s.Properties.Lookup(field); ok {
+			p.Schema = tag
+		} else {
+			s.Properties = append(Properties{{Name: field, Schema: tag}}, s.Properties...)
+		}
+		if !contains(s.Required, field) {
+			s.Required = append([]string{field}, s.Required...)
+		}
+		return s
+	})
+}
+
+// define adds the schema built by fn to the definitions, unless already defined, and returns the reference
+// to it. Definition is registered before it's built so that recursive declarations terminate.
+func (b *Builder) define(pkg, name string, fn func() *Schema) *Schema {
+	if b.decls.Declare(pkg, name) {
+		s := &Schema{}
+		b.defs[name] = s
+		*s = *fn()
+	}
+	return &Schema{Ref: b.prefix + name}
+}
+
+func (b *Builder) object(s *code.Struct) *Schema {
+	res := &Schema{Type: "object"}
+	if s == nil {
+		return res
+	}
+	b.fields(res, s)
+	return res
+}
+
+func (b *Builder) fields(res *Schema, s *code.Struct) {
+	for _, f := range s.JSONFields() {
+		if f.OmitEmpty {
+			// nil values are omitted
+			res.Properties = append(res.Properties, &Property{Name: f.Name, Schema: b.typeRef(f.Field.Type)})
+			continue
+		}
+		res.Properties = append(res.Properties, &Property{Name: f.Name, Schema: b.value(f.Field.Type)})
+		res.Required = append(res.Required, f.Name)
+	}
+}
+
+// value returns schema of the encoded value of the type, nil pointers, slices, maps and interfaces are
+// encoded as null.
+func (b *Builder) value(r *code.TypeRef) *Schema {
+	s := b.typeRef(r)
+	if r == nil || !(r.Kind == code.RefPointer || r.Kind == code.RefSlice || r.Kind == code.RefMap || r.Interface != nil) {
+		return s
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+func (b *Builder) typeRef(r *code.TypeRef) *Schema {
+	if r == nil {
+		return &Schema{}
+	}
+	switch r.Kind {
+	case code.RefPointer:
+		return b.typeRef(r.Elem)
+	case code.RefSlice:
+		if r.IsBytes() {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: b.value(r.Elem)}
+	case code.RefMap:
+		return &Schema{Type: "object", AdditionalProperties: b.value(r.Elem)}
+	case code.RefIdent:
+		return b.ident(r)
+	default:
+		return &Schema{}
+	}
+}
+
+func (b *Builder) ident(r *code.TypeRef) *Schema {
+	switch {
+	case r.Interface != nil:
+		return b.define(r.Interface.Pkg, r.Interface.Name, func() *Schema {
+			return b.Interface(r.Interface)
+		})
+	case r.Struct != nil:
+		return b.define(r.Struct.Pkg, r.Struct.Name, func() *Schema {
+			return b.object(r.Struct)
+		})
+	case r.Pkg == "time" && r.Name == "Time":
+		return &Schema{Type: "string", Format: "date-time"}
+	case r.Pkg == "time" && r.Name == "Duration":
+		return &Schema{Type: "integer"}
+	}
+
+	// named basic types are described by their underlying types
+	switch r.Basic() {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "float32", "float64":
+		return &Schema{Type: "number"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return &Schema{Type: "integer"}
+	default:
+		return &Schema{}
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+// userInterface returns discriminated User interface with the variants referencing Contact interface.
+func userInterface() *code.Interface {
+	contact := &code.Interface{Name: "Contact", Pkg: "github.com/eugenenosenko/gopoly/models"}
+	contact.Variants = code.VariantList{
+		{Name: "EmailContact", Interface: contact, Struct: &code.Struct{
+			Name: "EmailContact",
+			Fields: code.FieldList{
+				{Name: "Email", Tags: "`json:\"email\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+			},
+		}},
+		{Name: "PhoneContact", Interface: contact, Struct: &code.Struct{
+			Name: "PhoneContact",
+			Fields: code.FieldList{
+				{Name: "Phone", Tags: "`json:\"phone\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+			},
+		}},
+	}
+
+	base := &code.Struct{Name: "base", Fields: code.FieldList{
+		{Name: "ID", Tags: "`json:\"id\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+		{Name: "CreatedAt", Tags: "`json:\"created_at\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Time", Pkg: "time"}},
+	}}
+	user := &code.Interface{
+		Name:          "User",
+		Pkg:           "github.com/eugenenosenko/gopoly/models",
+		Discriminator: &code.Discriminator{Field: "kind", Mapping: map[string]string{"ADMIN": "Admin", "REGULAR": "Regular", "GUEST": "Regular"}},
+	}
+	user.Variants = code.VariantList{
+		{Name: "Regular", Interface: user, Struct: &code.Struct{
+			Name: "Regular",
+			Fields: code.FieldList{
+				{Name: "base", Type: &code.TypeRef{Kind: code.RefIdent, Name: "base", Struct: base}, Embedded: true},
+				{Name: "Kind", Tags: "`json:\"kind\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "Age", Tags: "`json:\"age,omitempty\"`", Type: &code.TypeRef{
+					Kind: code.RefPointer,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "int"},
+				}},
+				{Name: "Contacts", Tags: "`json:\"contacts\"`", Type: &code.TypeRef{
+					Kind: code.RefSlice,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Contact", Interface: contact},
+				}},
+				{Name: "Labels", Tags: "`json:\"labels,omitempty\"`", Type: &code.TypeRef{
+					Kind: code.RefMap,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "float64"},
+				}},
+				{Name: "Score", Tags: "`json:\"score,omitempty\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Score", Underlying: "float64"}},
+				{Name: "Secret", Tags: "`json:\"-\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "internal", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+			},
+		}},
+		{Name: "Admin", Interface: user, Struct: &code.Struct{
+			Name: "Admin",
+			Fields: code.FieldList{
+				{Name: "Root", Type: &code.TypeRef{Kind: code.RefIdent, Name: "bool"}},
+				{Name: "Key", Tags: "`json:\"key\"`", Type: &code.TypeRef{
+					Kind: code.RefSlice,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "byte"},
+				}},
+			},
+		}},
+		// not in the mapping, can't be decoded
+		{Name: "Deleted", Interface: user, Struct: &code.Struct{Name: "Deleted"}},
+	}
+	return user
+}
+
+func TestDocument(t *testing.T) {
+	t.Run("should describe discriminated interface and its nested interfaces", func(t *testing.T) {
+		doc, err := Document(userInterface())
+		require.NoError(t, err)
+		actual, err := json.MarshalIndent(doc, "", "  ")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile("testdata/user.golden.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(data), string(actual))
+	})
+
+	t.Run("should accept encoded variants with nil values", func(t *testing.T) {
+		doc, err := Document(userInterface())
+		require.NoError(t, err)
+
+		type admin struct {
+			Kind string `json:"kind"`
+			Root bool
+			Key  []byte `json:"key"`
+		}
+		type regular struct {
+			ID        string    `json:"id"`
+			CreatedAt time.Time `json:"created_at"`
+			Kind      string    `json:"kind"`
+			Age       *int      `json:"age,omitempty"`
+			Contacts  []any     `json:"contacts"`
+		}
+		for _, v := range []any{
+			admin{Kind: "ADMIN"},
+			regular{Kind: "GUEST"},
+			regular{Kind: "REGULAR", Contacts: []any{nil, map[string]string{"email": "user@example.com"}}},
+		} {
+			data, err := json.Marshal(v)
+			require.NoError(t, err)
+			var value any
+			require.NoError(t, json.Unmarshal(data, &value))
+			require.NoError(t, validate(doc, doc, value), string(data))
+		}
+		require.Error(t, validate(doc, doc, map[string]any{"kind": "ADMIN", "Root": false, "key": 1.0}))
+	})
+}
+
+// validate returns the first violation of the schema by the value decoded from JSON. Only the keywords emitted
+// by the Builder are supported, references are resolved against the definitions of the root.
+func validate(root, s *Schema, v any) error {
+	switch {
+	case s.never:
+		return fmt.Errorf("%v isn't allowed", v)
+	case s.Ref != "":
+		return validate(root, root.Defs[strings.TrimPrefix(s.Ref, DefsPrefix)], v)
+	case len(s.AnyOf) > 0:
+		for _, o := range s.AnyOf {
+			if validate(root, o, v) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v doesn't match any of the schemas", v)
+	case len(s.OneOf) > 0:
+		var matched int
+		for _, o := range s.OneOf {
+			if validate(root, o, v) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%v matches %d of the schemas", v, matched)
+		}
+		return nil
+	}
+
+	if s.Type != "" && !isType(s.Type, v) {
+		return fmt.Errorf("%v isn't %s", v, s.Type)
+	}
+	if s.Const != nil && s.Const != v {
+		return fmt.Errorf("%v isn't %v", v, s.Const)
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		return fmt.Errorf("%v isn't one of %v", v, s.Enum)
+	}
+	switch vv := v.(type) {
+	case map[string]any:
+		for _, r := range s.Required {
+			if _, ok := vv[r]; !ok {
+				return fmt.Errorf("%s is required", r)
+			}
+		}
+		for k, e := range vv {
+			if p, ok := s.Properties.Lookup(k); ok {
+				if err := validate(root, p.Schema, e); err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+			} else if s.AdditionalProperties != nil {
+				if err := validate(root, s.AdditionalProperties, e); err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+			}
+		}
+	case []any:
+		for i, e := range vv {
+			if s.Items == nil {
+				break
+			}
+			if err := validate(root, s.Items, e); err != nil {
+				return fmt.Errorf("%d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isType(typ string, v any) bool {
+	switch vv := v.(type) {
+	case nil:
+		return typ == "null"
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case float64:
+		return typ == "number" || typ == "integer" && vv == math.Trunc(vv)
+	case map[string]any:
+		return typ == "object"
+	case []any:
+		return typ == "array"
+	default:
+		return false
+	}
+}
+
+func containsValue(values []any, v any) bool {
+	for _, e := range values {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func TestComponents(t *testing.T) {
+	t.Run("should fail when declarations of different packages have the same name", func(t *testing.T) {
+		order := &code.Interface{Name: "OrderEvent", Pkg: "github.com/eugenenosenko/gopoly/orders"}
+		order.Variants = code.VariantList{{Name: "Created", Interface: order, Struct: &code.Struct{Name: "Created"}}}
+		user := &code.Interface{Name: "UserEvent", Pkg: "github.com/eugenenosenko/gopoly/users"}
+		user.Variants = code.VariantList{{Name: "Created", Interface: user, Struct: &code.Struct{Name: "Created"}}}
+
+		_, err := Components(code.InterfaceList{order, user})
+		require.EqualError(t, err, "jsonschema: github.com/eugenenosenko/gopoly/orders.Created and "+
+			"github.com/eugenenosenko/gopoly/users.Created are both declared as Created")
+	})
+}
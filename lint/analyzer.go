@@ -0,0 +1,228 @@
+// Package lint provides analysis.Analyzer that checks Go code against the gopoly configuration.
+//
+// The analyzer reports:
+//   - type switches over configured interfaces that miss some of the variants
+//   - types implementing the marker method that are missing from the discriminator mapping
+//   - generated files of the analysed package that are missing or stale
+//
+// Analyzer only reports the analysed package, so that it can be run by the standard drivers, i.e. go vet.
+package lint
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/gqlgen"
+	"github.com/eugenenosenko/gopoly/internal/xslices"
+	"github.com/eugenenosenko/gopoly/openapi"
+)
+
+const doc = `check code against the gopoly configuration
+
+Reports type switches over configured interfaces that miss variants, types implementing
+the marker method that are missing from the discriminator mapping and generated files that
+are missing or stale.`
+
+type Config struct {
+	// ConfigProvider provides normalized config.Config, when nil the config is read from the files
+	// passed with the -config flag of the analyzer.
+	ConfigProvider func() (*config.Config, error)
+	// Generate returns the files generated for the config.Config by their absolute names, generated files
+	// of the analysed package are compared with them. Files are generated with poly.Client when nil.
+	Generate func(ctx context.Context, c *config.Config) (map[string][]byte, error)
+}
+
+type analyzer struct {
+	provider    func() (*config.Config, error)
+	generate    func(ctx context.Context, c *config.Config) (map[string][]byte, error)
+	configFiles string
+
+	once sync.Once
+	conf *config.Config
+	err  error
+}
+
+// NewAnalyzer returns analysis.Analyzer named 'gopoly'.
+func NewAnalyzer(c *Config) *analysis.Analyzer {
+	if c == nil {
+		c = &Config{}
+	}
+	a := &analyzer{provider: c.ConfigProvider, generate: c.Generate}
+	if a.provider == nil {
+		a.provider = a.configFromFile
+	}
+	if a.generate == nil {
+		a.generate = generate
+	}
+
+	res := &analysis.Analyzer{
+		Name: "gopoly",
+		Doc:  doc,
+		Run:  a.run,
+	}
+	res.Flags.StringVar(&a.configFiles, "config", ".gopoly.yaml",
+		"comma-separated config files that contain gopoly configuration, merged in order")
+	return res
+}
+
+func (a *analyzer) configFromFile() (*config.Config, error) {
+	r := config.Reader{OpenAPI: openapi.ImportTypes, GQLGen: gqlgen.ImportTypes}
+	c, err := r.Load(strings.Split(a.configFiles, ",")...)
+	if err != nil {
+		return nil, err
+	}
+	c.SetDefaults()
+	if err = c.Normalize(); err != nil {
+		return nil, errors.Wrapf(err, "normalizing config %s", a.configFiles)
+	}
+	return c, nil
+}
+
+func (a *analyzer) config() (*config.Config, error) {
+	a.once.Do(func() {
+		a.conf, a.err = a.provider()
+	})
+	return a.conf, a.err
+}
+
+func (a *analyzer) run(pass *analysis.Pass) (any, error) {
+	c, err := a.config()
+	if err != nil {
+		return nil, errors.Wrap(err, "gopoly: reading configuration")
+	}
+	defs := make(map[string]*config.TypeDefinition, len(c.Types))
+	for _, t := range c.Types {
+		defs[qualifiedName(t.Package, t.Name)] = t
+	}
+
+	checkTypeSwitches(pass, defs)
+	checkMarkerMethods(pass, defs)
+	if err = a.checkGeneratedFiles(pass, c); err != nil {
+		return nil, errors.Wrap(err, "gopoly: generating files")
+	}
+	return nil, nil
+}
+
+// checkTypeSwitches reports type switches over configured interfaces that don't list every variant.
+// Default clause doesn't make type switch exhaustive.
+func checkTypeSwitches(pass *analysis.Pass, defs map[string]*config.TypeDefinition) {
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSwitchStmt)
+			if !ok {
+				return true
+			}
+			named, ok := pass.TypesInfo.TypeOf(typeSwitchSubject(ts)).(*types.Named)
+			if !ok || named.Obj().Pkg() == nil {
+				return true
+			}
+			iface := named.Obj()
+			def, ok := defs[qualifiedName(iface.Pkg().Path(), iface.Name())]
+			if !ok {
+				return true
+			}
+
+			covered := make(map[string]struct{}, 0)
+			for _, stmt := range ts.Body.List {
+				clause, ok := stmt.(*ast.CaseClause)
+				if !ok {
+					continue
+				}
+				for _, e := range clause.List {
+					if obj := namedObject(pass.TypesInfo.TypeOf(e)); obj != nil && obj.Pkg() == iface.Pkg() {
+						covered[obj.Name()] = struct{}{}
+					}
+				}
+			}
+
+			missing := xslices.Difference(maps.Keys(covered), variants(def, iface.Pkg()))
+			if len(missing) > 0 {
+				sort.Strings(missing)
+				pass.Reportf(ts.Pos(), "type switch on %s.%s is missing variants: %s",
+					iface.Pkg().Name(), iface.Name(), strings.Join(missing, ", "))
+			}
+			return true
+		})
+	}
+}
+
+// checkMarkerMethods reports types that implement the marker method of the interface with
+// discriminator decoding, but are missing from the discriminator mapping.
+func checkMarkerMethods(pass *analysis.Pass, defs map[string]*config.TypeDefinition) {
+	for _, def := range defs {
+		if def.Package != pass.Pkg.Path() || !def.DecodingStrategy.IsDiscriminator() {
+			continue
+		}
+		mapped := xslices.ToSet[[]string](maps.Values(def.Discriminator.Mapping))
+		for _, obj := range implementations(pass.Pkg, def) {
+			if _, ok := mapped[obj.Name()]; !ok {
+				pass.Reportf(obj.Pos(), "%s implements marker method %s of %s but is missing from the discriminator mapping",
+					obj.Name(), def.MarkerMethod, def.Name)
+			}
+		}
+	}
+}
+
+// variants returns names of the variants of the configured interface. Those are discriminator mapping values
+// for discriminator decoding and all types implementing the marker method otherwise.
+func variants(def *config.TypeDefinition, pkg *types.Package) []string {
+	if def.DecodingStrategy.IsDiscriminator() {
+		return maps.Keys(xslices.ToSet[[]string](maps.Values(def.Discriminator.Mapping)))
+	}
+	return xslices.Map[[]*types.TypeName, []string](implementations(pkg, def), func(t *types.TypeName) string {
+		return t.Name()
+	})
+}
+
+// implementations returns non-interface types declared in the package that have the marker method.
+func implementations(pkg *types.Package, def *config.TypeDefinition) []*types.TypeName {
+	var res []*types.TypeName
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+			continue
+		}
+		m, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), false, pkg, def.MarkerMethod)
+		if _, ok := m.(*types.Func); ok {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+func typeSwitchSubject(ts *ast.TypeSwitchStmt) ast.Expr {
+	var e ast.Expr
+	switch s := ts.Assign.(type) {
+	case *ast.ExprStmt:
+		e = s.X
+	case *ast.AssignStmt:
+		e, _ = xslices.First(s.Rhs)
+	}
+	if ta, ok := e.(*ast.TypeAssertExpr); ok {
+		return ta.X
+	}
+	return e
+}
+
+func namedObject(t types.Type) *types.TypeName {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if n, ok := t.(*types.Named); ok {
+		return n.Obj()
+	}
+	return nil
+}
+
+func qualifiedName(pkg, name string) string {
+	return pkg + "." + name
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func newAnalyzer(c *config.Config) *Config {
+	return &Config{
+		ConfigProvider: func() (*config.Config, error) {
+			c.SetDefaults()
+			return c, c.Normalize()
+		},
+		Generate: func(context.Context, *config.Config) (map[string][]byte, error) {
+			return nil, nil
+		},
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	t.Run("should report missing variants and unmapped variants", func(t *testing.T) {
+		a := NewAnalyzer(newAnalyzer(&config.Config{
+			Types: config.TypesList{
+				{
+					Name: "Event",
+					Discriminator: config.DiscriminatorDefinition{
+						Field:   "type",
+						Mapping: map[string]string{"CREATED": "Created", "DELETED": "Deleted"},
+					},
+				},
+			},
+			Package: "a",
+		}))
+
+		analysistest.Run(t, analysistest.TestData(), a, "a", "c")
+	})
+
+	t.Run("should report missing variants of marker method implementations", func(t *testing.T) {
+		a := NewAnalyzer(newAnalyzer(&config.Config{
+			Types:   config.TypesList{{Name: "Shape"}},
+			Package: "b",
+		}))
+
+		analysistest.Run(t, analysistest.TestData(), a, "b")
+	})
+
+	t.Run("should report missing and stale generated files of the package", func(t *testing.T) {
+		dir := filepath.Join(analysistest.TestData(), "src", "d")
+		stale := filepath.Join(dir, "gopoly.gen.go")
+		current, err := os.ReadFile(filepath.Join(dir, "d.go"))
+		require.NoError(t, err)
+
+		c := newAnalyzer(&config.Config{Types: config.TypesList{{Name: "Animal"}}, Package: "d"})
+		c.Generate = func(context.Context, *config.Config) (map[string][]byte, error) {
+			return map[string][]byte{
+				filepath.Join(dir, "d.go"):           current,
+				stale:                                []byte("package d\n"),
+				filepath.Join(dir, "missing.gen.go"): []byte("package d\n"),
+				filepath.Join(dir, "..", "other.go"): []byte("package other\n"),
+			}, nil
+		}
+
+		analysistest.Run(t, analysistest.TestData(), NewAnalyzer(c), "d")
+	})
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/generator"
+	"github.com/eugenenosenko/gopoly/poly"
+	"github.com/eugenenosenko/gopoly/source"
+)
+
+// checkGeneratedFiles reports generated files of the analysed package that are missing or differ from the ones
+// generated for the configuration. Files are only generated for the packages of the configured types.
+func (a *analyzer) checkGeneratedFiles(pass *analysis.Pass, c *config.Config) error {
+	if len(pass.Files) == 0 {
+		return nil
+	}
+	configured := false
+	for _, t := range c.Types {
+		if t.Package == pass.Pkg.Path() {
+			configured = true
+			break
+		}
+	}
+	if !configured {
+		return nil
+	}
+
+	files, err := a.generate(context.Background(), c)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+	names := maps.Keys(files)
+	sort.Strings(names)
+	for _, name := range names {
+		if filepath.Dir(name) != dir {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "reading %s", name)
+		}
+		switch {
+		case os.IsNotExist(err):
+			pass.Reportf(pass.Files[0].Package, "generated file %s is missing, run gopoly generate", filepath.Base(name))
+		case !bytes.Equal(data, files[name]):
+			pos := pass.Files[0].Package
+			for _, f := range pass.Files {
+				if pass.Fset.File(f.Pos()).Name() == name {
+					pos = f.Package
+				}
+			}
+			pass.Reportf(pos, "generated file %s is out-of-date, run gopoly generate", filepath.Base(name))
+		}
+	}
+	return nil
+}
+
+// generate runs poly.Client over the configuration, collecting the generated files in memory.
+func generate(ctx context.Context, c *config.Config) (map[string][]byte, error) {
+	logf := func(string, ...any) {}
+	loader, err := source.NewLoader(&source.Config{Logf: logf, LoadFunc: source.LoadFromPackage})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating source.Loader")
+	}
+	files := memoryFiles{}
+	gen, err := generator.NewTemplateGenerator(&generator.Config{Logf: logf, Provider: files})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating codegen.Generator")
+	}
+	client, err := poly.NewClient(&poly.Config{Logf: logf, SourceLoader: loader, CodeGenerator: gen})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating poly.Client")
+	}
+	if err = client.Run(ctx, c); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]byte, len(files))
+	for name, buf := range files {
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving path of %s", name)
+		}
+		res[abs] = buf.Bytes()
+	}
+	return res, nil
+}
+
+// memoryFiles collects generated files in memory by their names.
+type memoryFiles map[string]*bytes.Buffer
+
+func (m memoryFiles) Provide(name string) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	m[name] = buf
+	return nopCloser{buf}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
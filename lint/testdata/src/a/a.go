@@ -0,0 +1,42 @@
+package a
+
+type Event interface {
+	IsEvent()
+}
+
+type Created struct {
+	ID string `json:"id"`
+}
+
+func (Created) IsEvent() {}
+
+type Deleted struct {
+	ID string `json:"id"`
+}
+
+func (*Deleted) IsEvent() {}
+
+type Archived struct { // want "Archived implements marker method IsEvent of Event but is missing from the discriminator mapping"
+	ID string `json:"id"`
+}
+
+func (Archived) IsEvent() {}
+
+func handle(e Event) string {
+	switch e.(type) { // want "type switch on a.Event is missing variants: Deleted"
+	case *Created:
+		return "created"
+	default:
+		return "unknown"
+	}
+}
+
+func handleAll(e Event) string {
+	switch v := e.(type) {
+	case Created, *Deleted:
+		return "known"
+	default:
+		_ = v
+		return "unknown"
+	}
+}
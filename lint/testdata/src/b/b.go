@@ -0,0 +1,25 @@
+package b
+
+type Shape interface {
+	IsShape()
+}
+
+type Circle struct {
+	Radius int `json:"radius"`
+}
+
+func (Circle) IsShape() {}
+
+type Square struct {
+	Side int `json:"side"`
+}
+
+func (Square) IsShape() {}
+
+func name(s Shape) string {
+	switch s.(type) { // want "type switch on b.Shape is missing variants: Square"
+	case Circle:
+		return "circle"
+	}
+	return "unknown"
+}
@@ -0,0 +1,15 @@
+package c
+
+import (
+	"a"
+)
+
+func handle(e a.Event) string {
+	switch e := e.(type) { // want "type switch on a.Event is missing variants: Created, Deleted"
+	case a.Archived:
+		return "archived"
+	default:
+		_ = e
+		return "unknown"
+	}
+}
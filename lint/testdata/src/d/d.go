@@ -0,0 +1,9 @@
+package d // want "generated file missing.gen.go is missing, run gopoly generate"
+
+type Animal interface {
+	IsAnimal()
+}
+
+type Cat struct{}
+
+func (Cat) IsAnimal() {}
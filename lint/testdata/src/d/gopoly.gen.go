@@ -0,0 +1,5 @@
+package d // want "generated file gopoly.gen.go is out-of-date, run gopoly generate"
+
+func UnmarshalAnimalJSON(data []byte) (Animal, error) {
+	return Cat{}, nil
+}
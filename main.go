@@ -3,19 +3,32 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
+
+	"golang.org/x/tools/go/analysis/unitchecker"
 
 	"github.com/eugenenosenko/gopoly/cli"
+	"github.com/eugenenosenko/gopoly/lint"
 )
 
 func main() {
-	app := cli.App{
-		Logf:           log.Printf,
-		RecoveryFunc:   cli.PrintTraceAndExit,
-		Ctx:            context.Background(),
-		ConfigProvider: cli.MergedConfigProvider,
+	// gopoly lint runs go vet with gopoly as the vet tool
+	if os.Getenv(cli.VetToolEnv) == "1" {
+		unitchecker.Main(lint.NewAnalyzer(nil))
+	}
+	app := &cli.App{
+		Logf:   log.Printf,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Info:   newRunInfo(),
 	}
-	app.Execute(newRunInfo())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := app.Run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
 }
 
 func newRunInfo() *cli.RunInfo {
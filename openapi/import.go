@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+// Union is a schema of the OpenAPI document composed with oneOf, i.e. interface with its variants.
+// Names are converted to the Go type names the way oapi-codegen does it.
+type Union struct {
+	Name     string
+	Variants []string
+	// Discriminator is the propertyName of the discriminator object, empty if schema has none.
+	Discriminator string
+	// Mapping of discriminator values to the variant names, implicit mapping uses the schema names as values.
+	Mapping map[string]string
+}
+
+type spec struct {
+	Components struct {
+		Schemas map[string]*specSchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type specSchema struct {
+	OneOf []struct {
+		Ref string `yaml:"$ref"`
+	} `yaml:"oneOf"`
+	Discriminator *struct {
+		PropertyName string            `yaml:"propertyName"`
+		Mapping      map[string]string `yaml:"mapping"`
+	} `yaml:"discriminator"`
+}
+
+// ReadUnions reads OpenAPI document in YAML or JSON format and returns its unions.
+func ReadUnions(filename string) ([]*Union, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "openapi.ReadUnions: reading filename %s", filename)
+	}
+	res, err := ParseUnions(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "openapi.ReadUnions: parsing filename %s", filename)
+	}
+	return res, nil
+}
+
+// ImportTypes reads OpenAPI document and returns type definitions of its unions, it's the config.Importer of
+// the from_openapi setting.
+func ImportTypes(filename string) (config.TypesList, error) {
+	unions, err := ReadUnions(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Types(unions), nil
+}
+
+// Types returns type definitions of the unions declared in the OpenAPI document. Unions with
+// discriminator use discriminator decoding, the rest is decoded with the strict decoding.
+func Types(unions []*Union) config.TypesList {
+	res := make(config.TypesList, 0, len(unions))
+	for _, u := range unions {
+		def := &config.TypeDefinition{Name: u.Name, Variants: u.Variants, DecodingStrategy: config.DecodingStrategyStrict}
+		if u.Discriminator != "" {
+			def.DecodingStrategy = config.DecodingStrategyDiscriminator
+			def.Discriminator = config.DiscriminatorDefinition{Field: u.Discriminator, Mapping: u.Mapping}
+		}
+		res = append(res, def)
+	}
+	return res
+}
+
+// ParseUnions returns unions declared in components.schemas of the OpenAPI document sorted by name.
+// Only the oneOf composed of references to other components is taken into account.
+func ParseUnions(data []byte) ([]*Union, error) {
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	res := make([]*Union, 0)
+	for name, schema := range s.Components.Schemas {
+		if schema == nil || len(schema.OneOf) == 0 {
+			continue
+		}
+		u := &Union{Name: TypeName(name)}
+		for _, o := range schema.OneOf {
+			ref, ok := componentName(o.Ref)
+			if !ok {
+				return nil, errors.Errorf("oneOf of %s contains inline schema, only $ref to components is supported", name)
+			}
+			u.Variants = append(u.Variants, TypeName(ref))
+		}
+
+		if d := schema.Discriminator; d != nil {
+			u.Discriminator = d.PropertyName
+			u.Mapping = make(map[string]string, 0)
+			for tag, ref := range d.Mapping {
+				if r, ok := componentName(ref); ok {
+					ref = r
+				}
+				u.Mapping[tag] = TypeName(ref)
+			}
+			// variants without explicit mapping are identified by the schema name
+			for _, o := range schema.OneOf {
+				ref, _ := componentName(o.Ref)
+				if !mapped(u.Mapping, TypeName(ref)) {
+					u.Mapping[ref] = TypeName(ref)
+				}
+			}
+		}
+		res = append(res, u)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// TypeName converts schema name to the Go type name, i.e. user_event -> UserEvent.
+func TypeName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	res := sb.String()
+	if res != "" && unicode.IsDigit(rune(res[0])) {
+		res = "N" + res
+	}
+	return res
+}
+
+func componentName(ref string) (string, bool) {
+	const prefix = "#/components/schemas/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref, prefix), true
+}
+
+func mapped(mapping map[string]string, variant string) bool {
+	for _, v := range mapping {
+		if v == variant {
+			return true
+		}
+	}
+	return false
+}
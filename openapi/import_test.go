@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func TestReadUnions(t *testing.T) {
+	t.Run("should read oneOf schemas with explicit and implicit discriminator mapping", func(t *testing.T) {
+		unions, err := ReadUnions("testdata/api.yaml")
+		require.NoError(t, err)
+
+		require.Equal(t, []*Union{
+			{
+				Name:     "Contact",
+				Variants: []string{"EmailContact", "PhoneContact"},
+			},
+			{
+				Name:          "UserEvent",
+				Variants:      []string{"UserCreated", "UserDeleted"},
+				Discriminator: "type",
+				Mapping:       map[string]string{"CREATED": "UserCreated", "user-deleted": "UserDeleted"},
+			},
+		}, unions)
+	})
+
+	t.Run("should reject inline oneOf schemas", func(t *testing.T) {
+		_, err := ParseUnions([]byte(`
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - type: object
+`))
+		require.EqualError(t, err, "oneOf of Pet contains inline schema, only $ref to components is supported")
+	})
+}
+
+func TestImportTypes(t *testing.T) {
+	t.Run("should decode unions with discriminator by the discriminator and the rest strictly", func(t *testing.T) {
+		types, err := ImportTypes("testdata/api.yaml")
+		require.NoError(t, err)
+
+		require.Equal(t, config.TypesList{
+			{
+				Name:             "Contact",
+				Variants:         []string{"EmailContact", "PhoneContact"},
+				DecodingStrategy: config.DecodingStrategyStrict,
+			},
+			{
+				Name:             "UserEvent",
+				Variants:         []string{"UserCreated", "UserDeleted"},
+				DecodingStrategy: config.DecodingStrategyDiscriminator,
+				Discriminator: config.DiscriminatorDefinition{
+					Field:   "type",
+					Mapping: map[string]string{"CREATED": "UserCreated", "user-deleted": "UserDeleted"},
+				},
+			},
+		}, types)
+	})
+}
+
+func TestTypeName(t *testing.T) {
+	t.Run("should convert schema names to Go type names", func(t *testing.T) {
+		require.Equal(t, "UserEvent", TypeName("user_event"))
+		require.Equal(t, "UserDeleted", TypeName("user-deleted"))
+		require.Equal(t, "PetV2", TypeName("Pet.v2"))
+		require.Equal(t, "N1pet", TypeName("1pet"))
+	})
+}
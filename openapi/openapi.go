@@ -0,0 +1,41 @@
+// Package openapi builds OpenAPI 3.1 documents containing components.schemas of the configured interfaces.
+// Interfaces are described with oneOf their variants and the discriminator object, the same way
+// the generated code decodes them.
+package openapi
+
+import (
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/jsonschema"
+)
+
+// Version of the OpenAPI specification of the documents.
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string      `json:"openapi"`
+	Info       *Info       `json:"info"`
+	Components *Components `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*jsonschema.Schema `json:"schemas"`
+}
+
+// New returns Document with the schemas of the interfaces. Document has no paths and is meant
+// to be referenced from, or merged into, the API description.
+func New(info *Info, ifaces code.InterfaceList) (*Document, error) {
+	schemas, err := jsonschema.Components(ifaces)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Components: &Components{Schemas: schemas},
+	}, nil
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should describe interfaces and variants as components schemas", func(t *testing.T) {
+		contact := &code.Interface{Name: "Contact", Pkg: "github.com/eugenenosenko/gopoly/models"}
+		contact.Variants = code.VariantList{
+			{Name: "EmailContact", Interface: contact, Struct: &code.Struct{
+				Name: "EmailContact",
+				Fields: code.FieldList{
+					{Name: "Email", Tags: "`json:\"email\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				},
+			}},
+		}
+		event := &code.Interface{
+			Name:          "Event",
+			Pkg:           "github.com/eugenenosenko/gopoly/models",
+			Discriminator: &code.Discriminator{Field: "type", Mapping: map[string]string{"CREATED": "CreatedEvent"}},
+		}
+		event.Variants = code.VariantList{
+			{Name: "CreatedEvent", Interface: event, Struct: &code.Struct{
+				Name: "CreatedEvent",
+				Fields: code.FieldList{
+					{Name: "Type", Tags: "`json:\"type\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+					{Name: "Contact", Tags: "`json:\"contact,omitempty\"`", Type: &code.TypeRef{
+						Kind: code.RefIdent, Name: "Contact", Interface: contact,
+					}},
+					{Name: "Tags", Tags: "`json:\"tags\"`", Type: &code.TypeRef{
+						Kind: code.RefSlice, Elem: &code.TypeRef{Kind: code.RefIdent, Name: "string"},
+					}},
+				},
+			}},
+		}
+
+		doc, err := New(&Info{Title: "events", Version: "1.0.0"}, code.InterfaceList{event, contact})
+		require.NoError(t, err)
+		actual, err := json.MarshalIndent(doc, "", "  ")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile("testdata/components.golden.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(data), string(actual))
+	})
+}
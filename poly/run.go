@@ -3,6 +3,8 @@ package poly
 import (
 	"context"
 	"path"
+	"path/filepath"
+	"sort"
 
 	"github.com/pkg/errors"
 
@@ -22,20 +24,45 @@ import (
 // If multiple output files have been provided and the types are located in the same package, then declarations
 // will be split between those files. If multiple types share the same output file but are located in the same package
 // declarations will be created in the same file.
+//
+// Client doesn't write files itself, codegen.Generator decides where the output goes, i.e. to compare it with
+// the files on disk.
 func (r *Client) Run(ctx context.Context, c *config.Config) error {
+	tasks, err := r.Tasks(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.Package != "" {
+			if task.Filename, err = outputFilename(task); err != nil {
+				return err
+			}
+		}
+		if err = r.Generator.Generate(task); err != nil {
+			return errors.Wrapf(err, "generating codegen")
+		}
+	}
+	return nil
+}
+
+// Tasks loads the sources and builds codegen.Task for every output file without generating them.
+// Filenames of the tasks are relative to the directory of the codegen.Task package. Tasks of the
+// config.TargetConfig documents, i.e. JSON schema, have no package and are relative to the working directory.
+func (r *Client) Tasks(ctx context.Context, c *config.Config) ([]*codegen.Task, error) {
 	if len(c.Types) == 0 {
 		r.Logf("No types provided or configuration is incorrect.")
-		return nil
+		return nil, nil
 	}
 
 	sources, err := r.Loader.Load(ctx, c.Types)
 	if err != nil {
-		return errors.Wrapf(err, "loading source from packages")
+		return nil, errors.Wrapf(err, "loading source from packages")
 	}
 
 	// each definition needs to be generated in its own package
 	// if definition has a separate output filename defined then output should go there
 	tasks := make([]*codegen.Task, 0)
+	generated := make(map[*config.TypeDefinition]*codegen.Type, 0)
 	psources := sources.AssociateByPkgName()
 	for filename, types := range c.Types.AssociateByOutput() {
 		for pkg, tts := range types.AssociateByPkgName() {
@@ -63,29 +90,228 @@ func (r *Client) Run(ctx context.Context, c *config.Config) error {
 					variants = xmaps.Merge(variants, iface.Variants.AssociateByVariantName())
 				}
 				// add type to to-be-generated data with its variants
-				d.Types = append(d.Types, &codegen.Type{
+				t := &codegen.Type{
 					Name:               iface.Name,
 					Variants:           variants,
 					DecodingStrategy:   def.DecodingStrategy.String(),
 					DiscriminatorField: def.Discriminator.Field,
-				})
+					Registry:           def.Registry,
+					SQLColumn:          def.SQLColumn,
+					Interface:          iface,
+				}
+				d.Types = append(d.Types, t)
+				generated[def] = t
 			}
+			sort.Slice(d.Types, func(i, j int) bool {
+				return d.Types[i].Name < d.Types[j].Name
+			})
 			tasks = append(tasks, &codegen.Task{
-				Filename: outputFilename(p, filename),
+				Filename: path.Base(filename),
+				Package:  p,
+				Dir:      src.Dir,
 				Template: templates.DefaultJSONTemplate(),
 				Input:    &d,
 			})
 		}
 	}
+	if err = checkGoSourceTargets(c.Types, generated); err != nil {
+		return nil, err
+	}
+	conversions, err := protoConversionTasks(c.Types, generated, psources)
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, conversions...)
+	return append(tasks, targetTasks(c.Types, generated, psources)...), nil
+}
 
-	for _, task := range tasks {
-		if err = r.Generator.Generate(task); err != nil {
-			return errors.Wrapf(err, "generating codegen")
+// targetTasks builds a codegen.Task for every config.TargetConfig filename, types sharing
+// the filename are rendered into the same document. Go source targets are generated in the
+// package of the types, types sharing the package and the filename share the file.
+func targetTasks(
+	defs config.TypesList,
+	generated map[*config.TypeDefinition]*codegen.Type,
+	psources map[code.Package]*code.Source,
+) []*codegen.Task {
+	tasks := make([]*codegen.Task, 0)
+	byFilename := make(map[string]*codegen.Task, 0)
+	for _, def := range defs {
+		t, ok := generated[def]
+		if !ok {
+			continue
+		}
+		for _, target := range def.Targets {
+			key := target.Filename
+			if target.Kind.IsGoSource() {
+				key = path.Join(def.Package, path.Base(target.Filename))
+			}
+			task, ok := byFilename[key]
+			if !ok {
+				task = &codegen.Task{
+					Filename: target.Filename,
+					Template: targetTemplate(target.Kind),
+					Input:    &codegen.Input{Options: xmaps.Merge(target.Options)},
+				}
+				if target.Kind.IsGoSource() {
+					p := code.Package(def.Package)
+					task.Filename = path.Base(target.Filename)
+					task.Package = p
+					task.Dir = psources[p].Dir
+					task.Input.Package = p.Name()
+					task.Input.Imports = psources[p].Imports
+				}
+				byFilename[key] = task
+				tasks = append(tasks, task)
+			} else if oneof := target.Options[config.ProtoOptionOneof]; target.Kind == config.TargetKindProto && oneof != "" {
+				// oneof numbers are configured per type, the rest of the options by the first type of the file
+				if numbers := task.Input.Options[config.ProtoOptionOneof]; numbers != "" {
+					oneof = numbers + "," + oneof
+				}
+				task.Input.Options[config.ProtoOptionOneof] = oneof
+			}
+			task.Input.Types = append(task.Input.Types, t)
+		}
+	}
+	return tasks
+}
+
+func targetTemplate(kind config.TargetKind) string {
+	switch kind {
+	case config.TargetKindJSONSchema:
+		return templates.JSONSchemaTemplate()
+	case config.TargetKindTypeScript:
+		return templates.TypeScriptTemplate()
+	case config.TargetKindGraphQL:
+		return templates.GraphQLTemplate()
+	case config.TargetKindProto:
+		return templates.ProtoTemplate()
+	case config.TargetKindMsgPack:
+		return templates.MsgPackTemplate()
+	case config.TargetKindCBOR:
+		return templates.CBORTemplate()
+	case config.TargetKindBSON:
+		return templates.BSONTemplate()
+	case config.TargetKindXML:
+		return templates.XMLTemplate()
+	default:
+		return ""
+	}
+}
+
+// checkGoSourceTargets makes sure that polymorphic fields of the types with Go source targets reference
+// interfaces with the same target, otherwise generated decoders would call undeclared functions.
+func checkGoSourceTargets(defs config.TypesList, generated map[*config.TypeDefinition]*codegen.Type) error {
+	kinds := make(map[string]map[config.TargetKind]struct{}, 0)
+	for _, def := range defs {
+		k := make(map[config.TargetKind]struct{}, 0)
+		for _, target := range def.Targets {
+			k[target.Kind] = struct{}{}
+		}
+		kinds[path.Join(def.Package, def.Name)] = k
+	}
+	for _, def := range defs {
+		t, ok := generated[def]
+		if !ok {
+			continue
+		}
+		for _, target := range def.Targets {
+			if !target.Kind.IsGoSource() {
+				continue
+			}
+			for _, v := range t.Variants {
+				for _, f := range v.Fields {
+					if target.Kind == config.TargetKindXML && f.Kind == code.KindMap {
+						return errors.Errorf("field %s of %s is a map that can't be decoded from XML", f.Name, v.Name)
+					}
+					if _, ok := kinds[path.Join(f.Interface.Pkg, f.Interface.Name)][target.Kind]; !ok {
+						return errors.Errorf(
+							"field %s of %s references %s that has no %s target", f.Name, v.Name, f.Interface.Name, target.Kind)
+					}
+				}
+			}
 		}
 	}
 	return nil
 }
 
-func outputFilename(pkg code.Package, filename string) string {
-	return path.Join(pkg.Dir(), path.Base(filename))
+// protoConversionTasks builds a codegen.Task per package with Go functions converting the types with
+// the proto target and go_package option to the protobuf messages and back. Types of the package have
+// to share the go_package.
+func protoConversionTasks(
+	defs config.TypesList,
+	generated map[*config.TypeDefinition]*codegen.Type,
+	psources map[code.Package]*code.Source,
+) ([]*codegen.Task, error) {
+	tasks := make([]*codegen.Task, 0)
+	byPackage := make(map[code.Package]*codegen.Task, 0)
+	for _, def := range defs {
+		t, ok := generated[def]
+		if !ok {
+			continue
+		}
+		for _, target := range def.Targets {
+			goPackage := target.Options[config.ProtoOptionGoPackage]
+			if target.Kind != config.TargetKindProto || goPackage == "" {
+				continue
+			}
+			p := code.Package(def.Package)
+			task, ok := byPackage[p]
+			if !ok {
+				filename := target.Options[config.ProtoOptionGoFilename]
+				if filename == "" {
+					filename = "proto.gen.go"
+				}
+				task = &codegen.Task{
+					Filename: path.Base(filename),
+					Package:  p,
+					Dir:      psources[p].Dir,
+					Template: templates.ProtoConversionsTemplate(),
+					Input: &codegen.Input{
+						Package: p.Name(),
+						Imports: psources[p].Imports,
+						Options: map[string]string{config.ProtoOptionGoPackage: goPackage},
+					},
+				}
+				byPackage[p] = task
+				tasks = append(tasks, task)
+			} else if gp := task.Input.Options[config.ProtoOptionGoPackage]; gp != goPackage {
+				return nil, errors.Errorf("types of package %s are converted to different go_package %s and %s", p, gp, goPackage)
+			}
+			task.Input.Types = append(task.Input.Types, t)
+		}
+	}
+	return tasks, nil
+}
+
+// outputFilename returns the filename of the task in the directory of its package, the code.Source.Dir
+// set by the source.Loader.
+func outputFilename(task *codegen.Task) (string, error) {
+	if task.Dir == "" {
+		return "", errors.Errorf("resolving output of %s: package %s is loaded without directory", task.Filename, task.Package)
+	}
+	return filepath.Join(task.Dir, path.Base(task.Filename)), nil
+}
+
+// Interfaces loads the sources and returns declarations of the configured interfaces in the order of
+// the configuration.
+func (r *Client) Interfaces(ctx context.Context, c *config.Config) (code.InterfaceList, error) {
+	sources, err := r.Loader.Load(ctx, c.Types)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading source from packages")
+	}
+	psources := sources.AssociateByPkgName()
+
+	res := make(code.InterfaceList, 0, len(c.Types))
+	for _, def := range c.Types {
+		src, ok := psources[code.Package(def.Package)]
+		if !ok {
+			return nil, errors.Errorf("missing source of package %s", def.Package)
+		}
+		iface, ok := src.Interfaces.AssociateByName()[def.Name]
+		if !ok {
+			return nil, errors.Errorf("missing declaration of %s in package %s", def.Name, def.Package)
+		}
+		res = append(res, iface)
+	}
+	return res, nil
 }
@@ -0,0 +1,200 @@
+package poly
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/codegen"
+	"github.com/eugenenosenko/gopoly/config"
+	"github.com/eugenenosenko/gopoly/templates"
+)
+
+const (
+	usersPkg  = "github.com/username/example/users"
+	ordersPkg = "github.com/username/example/orders"
+)
+
+func sources() map[code.Package]*code.Source {
+	return map[code.Package]*code.Source{
+		usersPkg: {
+			Package: usersPkg,
+			Dir:     "/src/users",
+			Imports: code.ImportList{{ShortName: "time", Path: "time"}},
+		},
+		ordersPkg: {Package: ordersPkg, Dir: "/src/orders"},
+	}
+}
+
+func TestTargetTasks(t *testing.T) {
+	t.Run("should render types sharing the target filename into the same document", func(t *testing.T) {
+		user := &config.TypeDefinition{Name: "User", Package: usersPkg, Targets: []*config.TargetConfig{
+			{Kind: config.TargetKindTypeScript, Filename: "ts/models.gen.ts"},
+			{Kind: config.TargetKindMsgPack, Filename: "msgpack.gen.go"},
+		}}
+		order := &config.TypeDefinition{Name: "Order", Package: ordersPkg, Targets: []*config.TargetConfig{
+			{Kind: config.TargetKindTypeScript, Filename: "ts/models.gen.ts"},
+			{Kind: config.TargetKindMsgPack, Filename: "msgpack.gen.go"},
+		}}
+		generated := map[*config.TypeDefinition]*codegen.Type{user: {Name: "User"}, order: {Name: "Order"}}
+
+		tasks := targetTasks(config.TypesList{user, order}, generated, sources())
+		require.Equal(t, []*codegen.Task{
+			{
+				Filename: "ts/models.gen.ts",
+				Template: templates.TypeScriptTemplate(),
+				Input: &codegen.Input{
+					Options: map[string]string{},
+					Types:   []*codegen.Type{generated[user], generated[order]},
+				},
+			},
+			{
+				Filename: "msgpack.gen.go",
+				Package:  usersPkg,
+				Dir:      "/src/users",
+				Template: templates.MsgPackTemplate(),
+				Input: &codegen.Input{
+					Package: "users",
+					Imports: code.ImportList{{ShortName: "time", Path: "time"}},
+					Options: map[string]string{},
+					Types:   []*codegen.Type{generated[user]},
+				},
+			},
+			{
+				Filename: "msgpack.gen.go",
+				Package:  ordersPkg,
+				Dir:      "/src/orders",
+				Template: templates.MsgPackTemplate(),
+				Input: &codegen.Input{
+					Package: "orders",
+					Options: map[string]string{},
+					Types:   []*codegen.Type{generated[order]},
+				},
+			},
+		}, tasks)
+	})
+	t.Run("should merge oneof numbers of the types sharing the .proto file", func(t *testing.T) {
+		user := &config.TypeDefinition{Name: "User", Package: usersPkg, Targets: []*config.TargetConfig{{
+			Kind:     config.TargetKindProto,
+			Filename: "users.proto",
+			Options:  map[string]string{config.ProtoOptionPackage: "users.v1", config.ProtoOptionOneof: "Admin:1"},
+		}}}
+		contact := &config.TypeDefinition{Name: "Contact", Package: usersPkg, Targets: []*config.TargetConfig{{
+			Kind:     config.TargetKindProto,
+			Filename: "users.proto",
+			Options:  map[string]string{config.ProtoOptionPackage: "users.v1", config.ProtoOptionOneof: "Email:1"},
+		}}}
+		generated := map[*config.TypeDefinition]*codegen.Type{user: {Name: "User"}, contact: {Name: "Contact"}}
+
+		tasks := targetTasks(config.TypesList{user, contact}, generated, sources())
+		require.Len(t, tasks, 1)
+		require.Equal(t, map[string]string{
+			config.ProtoOptionPackage: "users.v1",
+			config.ProtoOptionOneof:   "Admin:1,Email:1",
+		}, tasks[0].Input.Options)
+		require.Equal(t, "Admin:1", user.Targets[0].Options[config.ProtoOptionOneof], "options of the config are kept")
+	})
+	t.Run("should skip types that weren't generated", func(t *testing.T) {
+		user := &config.TypeDefinition{Name: "User", Package: usersPkg, Targets: []*config.TargetConfig{
+			{Kind: config.TargetKindJSONSchema, Filename: "user.json"},
+		}}
+		require.Empty(t, targetTasks(config.TypesList{user}, map[*config.TypeDefinition]*codegen.Type{}, sources()))
+	})
+}
+
+func TestCheckGoSourceTargets(t *testing.T) {
+	contact := &code.Interface{Name: "Contact", Pkg: usersPkg}
+	user := &code.Interface{Name: "User", Pkg: usersPkg}
+	types := func(kinds ...config.TargetKind) (config.TypesList, map[*config.TypeDefinition]*codegen.Type) {
+		userDef := &config.TypeDefinition{Name: "User", Package: usersPkg}
+		for _, kind := range kinds {
+			userDef.Targets = append(userDef.Targets, &config.TargetConfig{Kind: kind, Filename: string(kind) + ".gen.go"})
+		}
+		contactDef := &config.TypeDefinition{Name: "Contact", Package: usersPkg, Targets: []*config.TargetConfig{
+			{Kind: config.TargetKindMsgPack, Filename: "msgpack.gen.go"},
+		}}
+		generated := map[*config.TypeDefinition]*codegen.Type{
+			userDef: {Name: "User", Variants: map[string]*code.Variant{
+				"Admin": {Name: "Admin", Interface: user, Fields: code.PolyFieldList{
+					{Name: "Contacts", Interface: contact, Kind: code.KindMap},
+				}},
+			}},
+		}
+		return config.TypesList{userDef, contactDef}, generated
+	}
+
+	t.Run("should accept fields referencing interfaces with the same target", func(t *testing.T) {
+		defs, generated := types(config.TargetKindMsgPack, config.TargetKindJSONSchema)
+		require.NoError(t, checkGoSourceTargets(defs, generated))
+	})
+	t.Run("should reject fields referencing interfaces without the target", func(t *testing.T) {
+		defs, generated := types(config.TargetKindCBOR)
+		require.EqualError(t, checkGoSourceTargets(defs, generated),
+			"field Contacts of Admin references Contact that has no cbor target")
+	})
+	t.Run("should reject maps of the xml targets", func(t *testing.T) {
+		defs, generated := types(config.TargetKindXML)
+		require.EqualError(t, checkGoSourceTargets(defs, generated),
+			"field Contacts of Admin is a map that can't be decoded from XML")
+	})
+}
+
+func TestProtoConversionTasks(t *testing.T) {
+	proto := func(goPackage string) []*config.TargetConfig {
+		return []*config.TargetConfig{{
+			Kind:     config.TargetKindProto,
+			Filename: "users.proto",
+			Options:  map[string]string{config.ProtoOptionGoPackage: goPackage},
+		}}
+	}
+
+	t.Run("should convert types of the package in a single file", func(t *testing.T) {
+		user := &config.TypeDefinition{Name: "User", Package: usersPkg, Targets: proto("example/userspb")}
+		contact := &config.TypeDefinition{Name: "Contact", Package: usersPkg, Targets: proto("example/userspb")}
+		order := &config.TypeDefinition{Name: "Order", Package: ordersPkg, Targets: []*config.TargetConfig{
+			{Kind: config.TargetKindProto, Filename: "orders.proto"},
+		}}
+		generated := map[*config.TypeDefinition]*codegen.Type{
+			user: {Name: "User"}, contact: {Name: "Contact"}, order: {Name: "Order"},
+		}
+
+		tasks, err := protoConversionTasks(config.TypesList{user, contact, order}, generated, sources())
+		require.NoError(t, err)
+		require.Equal(t, []*codegen.Task{{
+			Filename: "proto.gen.go",
+			Package:  usersPkg,
+			Dir:      "/src/users",
+			Template: templates.ProtoConversionsTemplate(),
+			Input: &codegen.Input{
+				Package: "users",
+				Imports: code.ImportList{{ShortName: "time", Path: "time"}},
+				Options: map[string]string{config.ProtoOptionGoPackage: "example/userspb"},
+				Types:   []*codegen.Type{generated[user], generated[contact]},
+			},
+		}}, tasks)
+	})
+	t.Run("should reject types of the package converted to different go_package", func(t *testing.T) {
+		user := &config.TypeDefinition{Name: "User", Package: usersPkg, Targets: proto("example/userspb")}
+		contact := &config.TypeDefinition{Name: "Contact", Package: usersPkg, Targets: proto("example/contactspb")}
+		generated := map[*config.TypeDefinition]*codegen.Type{user: {Name: "User"}, contact: {Name: "Contact"}}
+
+		_, err := protoConversionTasks(config.TypesList{user, contact}, generated, sources())
+		require.EqualError(t, err, "types of package github.com/username/example/users are converted to "+
+			"different go_package example/userspb and example/contactspb")
+	})
+}
+
+func TestOutputFilename(t *testing.T) {
+	t.Run("should place the file into the directory of the package", func(t *testing.T) {
+		got, err := outputFilename(&codegen.Task{Filename: "internal/users.gen.go", Package: usersPkg, Dir: "/src/users"})
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join("/src/users", "users.gen.go"), got)
+	})
+	t.Run("should reject the package loaded without directory", func(t *testing.T) {
+		_, err := outputFilename(&codegen.Task{Filename: "gopoly.gen.go", Package: usersPkg})
+		require.EqualError(t, err, "resolving output of gopoly.gen.go: package "+
+			"github.com/username/example/users is loaded without directory")
+	})
+}
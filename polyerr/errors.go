@@ -0,0 +1,167 @@
+// Package polyerr contains typed errors returned by the code generated by gopoly.
+//
+// Every error carries a JSON pointer (RFC 6901) to the location in the payload
+// where decoding failed, i.e. "/user/contacts/1". Errors returned by the
+// generated functions can be inspected with errors.As:
+//
+//	var uv *polyerr.UnknownVariantError
+//	if errors.As(err, &uv) {
+//		log.Printf("unknown %s variant %q at %s", uv.Interface, uv.Value, uv.Path)
+//	}
+package polyerr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownVariantError is returned when the discriminator value doesn't match any of the
+// variants defined in the discriminator mapping.
+type UnknownVariantError struct {
+	Interface string
+	Value     string
+	Path      string
+}
+
+func (e *UnknownVariantError) Error() string {
+	return fmt.Sprintf("unknown %s variant %q%s", e.Interface, e.Value, at(e.Path))
+}
+
+func (e *UnknownVariantError) JSONPointer() string {
+	return e.Path
+}
+
+func (e *UnknownVariantError) withPath(p string) error {
+	c := *e
+	c.Path = p
+	return &c
+}
+
+// AmbiguousMatchError is returned by strict decoding when payload matches more than one variant.
+type AmbiguousMatchError struct {
+	Interface  string
+	Candidates []string
+	Path       string
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("data matches more than one of %s (%s)%s",
+		e.Interface,
+		strings.Join(e.Candidates, ", "),
+		at(e.Path),
+	)
+}
+
+func (e *AmbiguousMatchError) JSONPointer() string {
+	return e.Path
+}
+
+func (e *AmbiguousMatchError) withPath(p string) error {
+	c := *e
+	c.Path = p
+	return &c
+}
+
+// NoMatchError is returned by strict decoding when payload doesn't match any of the variants.
+type NoMatchError struct {
+	Interface string
+	Path      string
+}
+
+func (e *NoMatchError) Error() string {
+	return fmt.Sprintf("failed to match data to one of %s%s", e.Interface, at(e.Path))
+}
+
+func (e *NoMatchError) JSONPointer() string {
+	return e.Path
+}
+
+func (e *NoMatchError) withPath(p string) error {
+	c := *e
+	c.Path = p
+	return &c
+}
+
+// DecodeError wraps any other error that occurred during decoding, i.e. syntax errors, and
+// attaches the location to it.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v%s", e.Err, at(e.Path))
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DecodeError) JSONPointer() string {
+	return e.Path
+}
+
+func (e *DecodeError) withPath(p string) error {
+	c := *e
+	c.Path = p
+	return &c
+}
+
+type pathError interface {
+	error
+	JSONPointer() string
+	withPath(p string) error
+}
+
+// At prepends tokens to the JSON pointer of err. Errors that are not declared by this package
+// are wrapped into DecodeError. Tokens are escaped according to RFC 6901 and nil err returns nil.
+func At(err error, tokens ...any) error {
+	if err == nil {
+		return nil
+	}
+	pe, ok := err.(pathError) //nolint:errorlint // only the outermost error carries the path
+	if !ok {
+		pe = &DecodeError{Err: err}
+	}
+
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(escape(fmt.Sprint(t)))
+	}
+	sb.WriteString(pe.JSONPointer())
+	return pe.withPath(sb.String())
+}
+
+// Pointer returns JSON pointer of the first error in the chain that carries one.
+func Pointer(err error) string {
+	for err != nil {
+		if pe, ok := err.(pathError); ok { //nolint:errorlint // walking the chain manually
+			return pe.JSONPointer()
+		}
+		u, ok := err.(interface{ Unwrap() error }) //nolint:errorlint // walking the chain manually
+		if !ok {
+			return ""
+		}
+		err = u.Unwrap()
+	}
+	return ""
+}
+
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " at " + path
+}
+
+var (
+	_ pathError = (*UnknownVariantError)(nil)
+	_ pathError = (*AmbiguousMatchError)(nil)
+	_ pathError = (*NoMatchError)(nil)
+	_ pathError = (*DecodeError)(nil)
+)
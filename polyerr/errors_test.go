@@ -0,0 +1,48 @@
+package polyerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAt(t *testing.T) {
+	t.Run("should prepend tokens to the pointer of typed errors", func(t *testing.T) {
+		var err error = &UnknownVariantError{Interface: "User", Value: "ADMIN"}
+		err = At(err, "user")
+		err = At(err, "events", 3)
+
+		var uv *UnknownVariantError
+		require.True(t, errors.As(err, &uv))
+		require.Equal(t, "/events/3/user", uv.Path)
+		require.Equal(t, `unknown User variant "ADMIN" at /events/3/user`, err.Error())
+	})
+
+	t.Run("should wrap foreign errors into DecodeError", func(t *testing.T) {
+		cause := errors.New("unexpected end of JSON input")
+		err := At(cause, "contacts", "a/b~c")
+
+		var de *DecodeError
+		require.True(t, errors.As(err, &de))
+		require.Equal(t, "/contacts/a~1b~0c", de.Path)
+		require.ErrorIs(t, err, cause)
+	})
+
+	t.Run("should return nil for nil error", func(t *testing.T) {
+		require.NoError(t, At(nil, "user"))
+	})
+}
+
+func TestPointer(t *testing.T) {
+	t.Run("should return pointer of wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("handling request: %w", At(&NoMatchError{Interface: "Contact"}, "contacts", 1))
+
+		require.Equal(t, "/contacts/1", Pointer(err))
+	})
+
+	t.Run("should return empty pointer for unrelated errors", func(t *testing.T) {
+		require.Equal(t, "", Pointer(errors.New("boom")))
+	})
+}
@@ -0,0 +1,151 @@
+// Package polystream contains streaming helpers used by the code generated by gopoly.
+//
+// Stream decodes polymorphic elements of a JSON array or of a newline delimited JSON (NDJSON)
+// stream one at a time, without buffering the whole payload:
+//
+//	s := events.NewUserEventStream(r)
+//	for s.Next() {
+//		handle(s.Value())
+//	}
+//	if err := s.Err(); err != nil {
+//		return err
+//	}
+package polystream
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eugenenosenko/gopoly/polyerr"
+)
+
+// UnmarshalFunc decodes a single JSON value, i.e. generated Unmarshal<Interface>JSON function.
+type UnmarshalFunc[T any] func(data []byte) (T, error)
+
+// Stream iterates over elements of a JSON array or NDJSON stream. Format is detected from
+// the first non-whitespace character of the input.
+type Stream[T any] struct {
+	r         *bufio.Reader
+	dec       *json.Decoder
+	unmarshal UnmarshalFunc[T]
+	array     bool
+	index     int
+	value     T
+	err       error
+	done      bool
+}
+
+// New returns a Stream that reads elements from r and decodes them with unmarshal.
+func New[T any](r io.Reader, unmarshal UnmarshalFunc[T]) *Stream[T] {
+	return &Stream[T]{r: bufio.NewReader(r), unmarshal: unmarshal}
+}
+
+// Next decodes the next element. It returns false when the stream is exhausted or an error occurred,
+// which is then available via Err.
+func (s *Stream[T]) Next() bool {
+	if s.done {
+		return false
+	}
+	if s.dec == nil {
+		if err := s.start(); err != nil {
+			return s.fail(polyerr.At(err))
+		}
+	}
+	if !s.dec.More() {
+		if s.array {
+			// consume closing bracket of the array
+			if _, err := s.dec.Token(); err != nil {
+				return s.fail(polyerr.At(err))
+			}
+		}
+		if err := s.end(); err != nil {
+			return s.fail(polyerr.At(err))
+		}
+		s.done = true
+		return false
+	}
+
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		return s.fail(polyerr.At(err, s.index))
+	}
+	v, err := s.unmarshal(raw)
+	if err != nil {
+		return s.fail(polyerr.At(err, s.index))
+	}
+	s.value = v
+	s.index++
+	return true
+}
+
+// Value returns the element decoded by the last call to Next.
+func (s *Stream[T]) Value() T {
+	return s.value
+}
+
+// Err returns the first error that occurred while decoding the stream. JSON pointer of the error
+// starts with the index of the failed element.
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+func (s *Stream[T]) start() error {
+skip:
+	for {
+		b, err := s.r.Peek(1)
+		if err == io.EOF { //nolint:errorlint // bufio returns io.EOF as is
+			break
+		} else if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = s.r.ReadByte()
+		case '[':
+			s.array = true
+			break skip
+		default:
+			break skip
+		}
+	}
+
+	s.dec = json.NewDecoder(s.r)
+	if s.array {
+		// consume opening bracket of the array
+		if _, err := s.dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// end makes sure that only whitespace follows the elements, More reports no elements on the stray closing
+// delimiters as well.
+func (s *Stream[T]) end() error {
+	tok, err := s.dec.Token()
+	if err == io.EOF { //nolint:errorlint // json.Decoder returns io.EOF as is
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return fmt.Errorf("polystream: unexpected %v after the last element", tok)
+}
+
+func (s *Stream[T]) fail(err error) bool {
+	s.err, s.done = err, true
+	var zero T
+	s.value = zero
+	return false
+}
+
+// Decode reads a single JSON value from r and decodes it with unmarshal.
+func Decode[T any](r io.Reader, unmarshal UnmarshalFunc[T]) (T, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		var zero T
+		return zero, polyerr.At(err)
+	}
+	return unmarshal(raw)
+}
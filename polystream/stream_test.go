@@ -0,0 +1,99 @@
+package polystream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/polyerr"
+)
+
+type event struct {
+	Type string `json:"type"`
+}
+
+func unmarshalEvent(data []byte) (*event, error) {
+	var e event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Type == "" {
+		return nil, &polyerr.UnknownVariantError{Interface: "Event"}
+	}
+	return &e, nil
+}
+
+func collect(s *Stream[*event]) []string {
+	var res []string
+	for s.Next() {
+		res = append(res, s.Value().Type)
+	}
+	return res
+}
+
+func TestStream(t *testing.T) {
+	t.Run("should decode elements of JSON array", func(t *testing.T) {
+		s := New(strings.NewReader(` [{"type":"A"}, {"type":"B"}]`), unmarshalEvent)
+
+		require.Equal(t, []string{"A", "B"}, collect(s))
+		require.NoError(t, s.Err())
+	})
+
+	t.Run("should decode elements of NDJSON stream", func(t *testing.T) {
+		s := New(strings.NewReader("{\"type\":\"A\"}\n{\"type\":\"B\"}\n{\"type\":\"C\"}\n"), unmarshalEvent)
+
+		require.Equal(t, []string{"A", "B", "C"}, collect(s))
+		require.NoError(t, s.Err())
+	})
+
+	t.Run("should handle empty input", func(t *testing.T) {
+		for _, in := range []string{"", " \n", "[]"} {
+			s := New(strings.NewReader(in), unmarshalEvent)
+
+			require.Empty(t, collect(s), fmt.Sprintf("input %q", in))
+			require.NoError(t, s.Err())
+		}
+	})
+
+	t.Run("should stop and report index of the failed element", func(t *testing.T) {
+		s := New(strings.NewReader(`[{"type":"A"},{},{"type":"C"}]`), unmarshalEvent)
+
+		require.Equal(t, []string{"A"}, collect(s))
+		var uv *polyerr.UnknownVariantError
+		require.True(t, errors.As(s.Err(), &uv))
+		require.Equal(t, "/1", uv.Path)
+		require.False(t, s.Next())
+	})
+
+	t.Run("should report malformed input", func(t *testing.T) {
+		s := New(strings.NewReader(`[{"type":"A"},{"type":`), unmarshalEvent)
+
+		require.Equal(t, []string{"A"}, collect(s))
+		var de *polyerr.DecodeError
+		require.True(t, errors.As(s.Err(), &de))
+		require.Equal(t, "/1", de.Path)
+	})
+
+	t.Run("should report unbalanced delimiters after the last element", func(t *testing.T) {
+		for _, in := range []string{"{\"type\":\"A\"}\n]", "{\"type\":\"A\"}\n}\n", `[{"type":"A"}]]`, `[{"type":"A"}] []`} {
+			s := New(strings.NewReader(in), unmarshalEvent)
+
+			require.Equal(t, []string{"A"}, collect(s), fmt.Sprintf("input %q", in))
+			var de *polyerr.DecodeError
+			require.True(t, errors.As(s.Err(), &de), fmt.Sprintf("input %q", in))
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("should decode single value from reader", func(t *testing.T) {
+		got, err := Decode(strings.NewReader(`{"type":"A"}`), unmarshalEvent)
+
+		require.NoError(t, err)
+		require.Equal(t, &event{Type: "A"}, got)
+	})
+}
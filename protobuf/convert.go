@@ -0,0 +1,411 @@
+package protobuf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+const timestampPath = "google.golang.org/protobuf/types/known/timestamppb"
+
+// Import of the generated Go source.
+type Import struct {
+	Alias string
+	Path  string
+}
+
+// GoSource is the Go source of the conversion functions with the imports it needs.
+type GoSource struct {
+	Code    string
+	Imports []*Import
+}
+
+// Conversions returns Go functions converting the interfaces of a single package to the messages generated by
+// protoc-gen-go into goPackage and back, i.e. UserEventToProto and UserEventFromProto. Structs of the package
+// referenced by the interfaces get unexported conversion functions. Interfaces of the package referenced by
+// the ones being converted have to be converted as well, interfaces of other packages are converted by their
+// own conversion functions.
+//
+// Qualifier returns the name the package with the import path is referenced by in the generated file.
+func Conversions(ifaces code.InterfaceList, goPackage string, qualifier func(path string) string) (*GoSource, error) {
+	if len(ifaces) == 0 {
+		return &GoSource{}, nil
+	}
+	b := newBuilder()
+	if err := b.build(ifaces); err != nil {
+		return nil, err
+	}
+
+	pkg := ifaces[0].Pkg
+	converted := ifaces.AssociateByName()
+	pbPath, pbName := GoImport(goPackage)
+	g := &goGen{
+		pkg:       pkg,
+		pb:        pbName,
+		qualifier: qualifier,
+		imports:   map[string]string{pbPath: pbName},
+	}
+	for _, m := range b.messages {
+		switch {
+		case m.Interface != nil && m.Interface.Pkg != pkg:
+			continue
+		case m.Interface != nil:
+			if _, ok := converted[m.Name]; !ok {
+				return nil, fmt.Errorf("protobuf: %s is referenced by the converted interfaces but isn't converted itself", m.Name)
+			}
+			g.iface(m)
+		case m.Struct.Pkg == pkg:
+			g.object(m)
+		}
+	}
+
+	res := &GoSource{Code: g.sb.String()}
+	for path, alias := range g.imports {
+		res.Imports = append(res.Imports, &Import{Alias: alias, Path: path})
+	}
+	sort.Slice(res.Imports, func(i, j int) bool {
+		return res.Imports[i].Path < res.Imports[j].Path
+	})
+	return res, nil
+}
+
+type goGen struct {
+	sb        strings.Builder
+	pkg       string
+	pb        string
+	qualifier func(path string) string
+	imports   map[string]string
+	depth     int
+}
+
+func (g *goGen) line(format string, args ...any) {
+	g.sb.WriteString(strings.Repeat("\t", g.depth))
+	g.sb.WriteString(fmt.Sprintf(format, args...))
+	g.sb.WriteString("\n")
+}
+
+func (g *goGen) open(format string, args ...any) {
+	g.line(format, args...)
+	g.depth++
+}
+
+func (g *goGen) close(s string) {
+	g.depth--
+	g.line(s)
+}
+
+// qualified returns name qualified with the package of the import path, unless it's the converted package.
+func (g *goGen) qualified(path, name string) string {
+	if path == "" || path == g.pkg {
+		return name
+	}
+	alias := g.qualifier(path)
+	g.imports[path] = alias
+	return alias + "." + name
+}
+
+func (g *goGen) iface(m *message) {
+	pbType := g.pb + "." + m.Name
+	g.line("")
+	g.line("// %sToProto converts %s to the %s message.", m.Name, m.Name, pbType)
+	g.open("func %sToProto(v %s) (*%s, error) {", m.Name, m.Name, pbType)
+	g.line("switch t := v.(type) {")
+	g.line("case nil:")
+	g.line("\treturn nil, nil")
+	for _, v := range m.Variants {
+		oneof := GoCamelCase(snakeCase(v.Name))
+		cases := []string{"t"}
+		if !v.PointerReceiver {
+			cases = append(cases, "&t")
+		}
+		for i, arg := range cases {
+			if i == 0 {
+				g.open("case *%s:", v.Name)
+			} else {
+				g.open("case %s:", v.Name)
+			}
+			g.line("m, err := %s(%s)", toFunc(v.Name), arg)
+			g.open("if err != nil {")
+			g.line("return nil, err")
+			g.close("}")
+			g.line("return &%s{%s: &%s_%s{%s: m}}, nil", pbType, GoCamelCase(OneofName), pbType, oneof, oneof)
+			g.depth--
+		}
+	}
+	g.line("default:")
+	g.line("\treturn nil, &polyerr.UnknownVariantError{Interface: %q, Value: fmt.Sprintf(\"%%T\", v)}", m.Name)
+	g.line("}")
+	g.close("}")
+
+	g.line("")
+	g.line("// %sFromProto converts %s message to %s.", m.Name, pbType, m.Name)
+	g.open("func %sFromProto(m *%s) (%s, error) {", m.Name, pbType, m.Name)
+	g.line("switch t := m.Get%s().(type) {", GoCamelCase(OneofName))
+	g.line("case nil:")
+	g.line("\treturn nil, nil")
+	for _, v := range m.Variants {
+		oneof := GoCamelCase(snakeCase(v.Name))
+		g.open("case *%s_%s:", pbType, oneof)
+		g.line("v, err := %s(t.%s)", fromFunc(v.Name), oneof)
+		// nil variant would be returned as non-nil interface
+		g.open("if err != nil || v == nil {")
+		g.line("return nil, err")
+		g.close("}")
+		g.line("return v, nil")
+		g.depth--
+	}
+	g.line("default:")
+	g.line("\treturn nil, &polyerr.UnknownVariantError{Interface: %q, Value: fmt.Sprintf(\"%%T\", t)}", m.Name)
+	g.line("}")
+	g.close("}")
+}
+
+func (g *goGen) object(m *message) {
+	pbType := g.pb + "." + m.Name
+	g.line("")
+	g.open("func %s(v *%s) (m *%s, err error) {", toFunc(m.Name), m.Name, pbType)
+	g.open("if v == nil {")
+	g.line("return nil, nil")
+	g.close("}")
+	g.line("m = &%s{}", pbType)
+	for _, f := range m.Fields {
+		g.toProto(f.Ref, "v."+f.GoName, "m."+GoCamelCase(f.Name))
+	}
+	g.line("return m, nil")
+	g.close("}")
+
+	g.line("")
+	g.open("func %s(m *%s) (v *%s, err error) {", fromFunc(m.Name), pbType, m.Name)
+	g.open("if m == nil {")
+	g.line("return nil, nil")
+	g.close("}")
+	g.line("v = &%s{}", m.Name)
+	for _, f := range m.Fields {
+		g.fromProto(f.Ref, "m."+GoCamelCase(f.Name), "v."+f.GoName)
+	}
+	g.line("return v, nil")
+	g.close("}")
+}
+
+// toProto writes statements assigning Go value src to the message field dst.
+func (g *goGen) toProto(ref *code.TypeRef, src, dst string) {
+	switch ref.Kind {
+	case code.RefPointer:
+		elem := ref.Elem
+		switch {
+		case elem.Struct != nil:
+			g.call(dst, g.toFunc(elem), src)
+		case isTime(elem):
+			g.open("if %s != nil {", src)
+			g.line("%s = %s(*%s)", dst, g.timestamp("New"), src)
+			g.close("}")
+		default:
+			g.open("if %s != nil {", src)
+			g.line("p := %s(*%s)", g.pbType(elem), src)
+			g.line("%s = &p", dst)
+			g.close("}")
+		}
+	case code.RefSlice, code.RefMap:
+		if ref.IsBytes() {
+			g.line("%s = %s", dst, src)
+			return
+		}
+		e, p := g.vars()
+		if ref.Kind == code.RefMap {
+			g.line("%s = make(map[string]%s, len(%s))", dst, g.pbType(deref(ref.Elem)), src)
+			g.open("for k%d, %s := range %s {", g.depth, e, src)
+		} else {
+			g.open("for _, %s := range %s {", e, src)
+		}
+		if isScalar(ref.Elem) {
+			// scalars are converted in place
+			p = fmt.Sprintf("%s(%s)", g.pbType(ref.Elem), e)
+		} else {
+			g.line("var %s %s", p, g.pbType(deref(ref.Elem)))
+			g.elemToProto(ref.Elem, e, p)
+		}
+		if ref.Kind == code.RefMap {
+			g.line("%s[k%d] = %s", dst, g.depth-1, p)
+		} else {
+			g.line("%s = append(%s, %s)", dst, dst, p)
+		}
+		g.close("}")
+	default:
+		switch {
+		case ref.Interface != nil:
+			g.call(dst, g.qualified(ref.Interface.Pkg, ref.Interface.Name+"ToProto"), src)
+		case ref.Struct != nil:
+			g.call(dst, g.toFunc(ref), "&"+src)
+		case isTime(ref):
+			g.line("%s = %s(%s)", dst, g.timestamp("New"), src)
+		default:
+			g.line("%s = %s(%s)", dst, g.pbType(ref), src)
+		}
+	}
+}
+
+// elemToProto converts element of the collection, pointers to scalars can't be represented in collections
+// and are converted to zero values.
+func (g *goGen) elemToProto(ref *code.TypeRef, src, dst string) {
+	if ref.Kind != code.RefPointer || ref.Elem.Struct != nil {
+		g.toProto(ref, src, dst)
+		return
+	}
+	g.open("if %s != nil {", src)
+	g.toProto(ref.Elem, "(*"+src+")", dst)
+	g.close("}")
+}
+
+// fromProto writes statements assigning message field src to the Go value dst.
+func (g *goGen) fromProto(ref *code.TypeRef, src, dst string) {
+	switch ref.Kind {
+	case code.RefPointer:
+		elem := ref.Elem
+		switch {
+		case elem.Struct != nil:
+			g.call(dst, g.fromFunc(elem), src)
+		case isTime(elem):
+			g.open("if %s != nil {", src)
+			g.line("t := %s.AsTime()", src)
+			g.line("%s = &t", dst)
+			g.close("}")
+		default:
+			g.open("if %s != nil {", src)
+			g.line("p := %s(*%s)", g.goType(elem), src)
+			g.line("%s = &p", dst)
+			g.close("}")
+		}
+	case code.RefSlice, code.RefMap:
+		if ref.IsBytes() {
+			g.line("%s = %s", dst, src)
+			return
+		}
+		e, p := g.vars()
+		if ref.Kind == code.RefMap {
+			g.line("%s = make(map[string]%s, len(%s))", dst, g.goType(ref.Elem), src)
+			g.open("for k%d, %s := range %s {", g.depth, e, src)
+		} else {
+			g.open("for _, %s := range %s {", e, src)
+		}
+		if isScalar(ref.Elem) {
+			p = fmt.Sprintf("%s(%s)", g.goType(ref.Elem), e)
+		} else {
+			g.line("var %s %s", p, g.goType(ref.Elem))
+			g.elemFromProto(ref.Elem, e, p)
+		}
+		if ref.Kind == code.RefMap {
+			g.line("%s[k%d] = %s", dst, g.depth-1, p)
+		} else {
+			g.line("%s = append(%s, %s)", dst, dst, p)
+		}
+		g.close("}")
+	default:
+		switch {
+		case ref.Interface != nil:
+			g.call(dst, g.qualified(ref.Interface.Pkg, ref.Interface.Name+"FromProto"), src)
+		case ref.Struct != nil:
+			s := fmt.Sprintf("s%d", g.depth)
+			g.open("{")
+			g.line("var %s *%s", s, ref.Struct.Name)
+			g.call(s, g.fromFunc(ref), src)
+			g.open("if %s != nil {", s)
+			g.line("%s = *%s", dst, s)
+			g.close("}")
+			g.close("}")
+		case isTime(ref):
+			g.open("if %s != nil {", src)
+			g.line("%s = %s.AsTime()", dst, src)
+			g.close("}")
+		default:
+			g.line("%s = %s(%s)", dst, g.goType(ref), src)
+		}
+	}
+}
+
+func (g *goGen) elemFromProto(ref *code.TypeRef, src, dst string) {
+	if ref.Kind != code.RefPointer || ref.Elem.Struct != nil || isTime(ref.Elem) {
+		g.fromProto(ref, src, dst)
+		return
+	}
+	g.line("p := %s(%s)", g.goType(ref.Elem), src)
+	g.line("%s = &p", dst)
+}
+
+// call writes call of the conversion function returning an error.
+func (g *goGen) call(dst, fn, arg string) {
+	g.open("if %s, err = %s(%s); err != nil {", dst, fn, arg)
+	g.line("return nil, err")
+	g.close("}")
+}
+
+// vars returns names of the loop variables unique for the nesting depth.
+func (g *goGen) vars() (elem, converted string) {
+	return fmt.Sprintf("e%d", g.depth), fmt.Sprintf("c%d", g.depth)
+}
+
+func (g *goGen) timestamp(fn string) string {
+	g.imports[timestampPath] = "timestamppb"
+	return "timestamppb." + fn
+}
+
+func (g *goGen) toFunc(ref *code.TypeRef) string {
+	return toFunc(deref(ref).Struct.Name)
+}
+
+func (g *goGen) fromFunc(ref *code.TypeRef) string {
+	return fromFunc(deref(ref).Struct.Name)
+}
+
+// pbType returns Go type of the message field generated by protoc-gen-go.
+func (g *goGen) pbType(ref *code.TypeRef) string {
+	switch {
+	case ref.Interface != nil:
+		return "*" + g.pb + "." + ref.Interface.Name
+	case ref.Struct != nil:
+		return "*" + g.pb + "." + ref.Struct.Name
+	case isTime(ref):
+		g.imports[timestampPath] = "timestamppb"
+		return "*timestamppb.Timestamp"
+	case isDuration(ref):
+		return "int64"
+	default:
+		return goScalars[scalar(ref)]
+	}
+}
+
+// goType returns Go type expression of the reference.
+func (g *goGen) goType(ref *code.TypeRef) string {
+	switch ref.Kind {
+	case code.RefPointer:
+		return "*" + g.goType(ref.Elem)
+	case code.RefSlice:
+		return "[]" + g.goType(ref.Elem)
+	case code.RefMap:
+		return "map[string]" + g.goType(ref.Elem)
+	}
+	switch {
+	case ref.Interface != nil:
+		return g.qualified(ref.Interface.Pkg, ref.Interface.Name)
+	case ref.Struct != nil:
+		return ref.Struct.Name
+	default:
+		return g.qualified(ref.Pkg, ref.Name)
+	}
+}
+
+func toFunc(name string) string {
+	return lowerFirst(name) + "ToProto"
+}
+
+func fromFunc(name string) string {
+	return lowerFirst(name) + "FromProto"
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
@@ -0,0 +1,315 @@
+// Package protobuf maps configured interfaces to Protocol Buffers messages. Interface becomes a wrapper message
+// with a oneof over its variants, variants and the structs they reference become messages built from the JSON
+// fields of the structs:
+//
+//	message UserEvent {
+//	  oneof value {
+//	    UserCreatedEvent user_created_event = 1;
+//	    UserDeletedEvent user_deleted_event = 2;
+//	  }
+//	}
+//
+// Besides the .proto file the package renders Go functions converting the interface values to the messages
+// generated by protoc-gen-go and back.
+package protobuf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+// OneofName is the name of the oneof of the wrapper messages.
+const OneofName = "value"
+
+// message is either a wrapper of the interface or a message of the struct.
+type message struct {
+	Name      string
+	Interface *code.Interface
+	Variants  code.VariantList
+	Struct    *code.Struct
+	Fields    []*field
+}
+
+type field struct {
+	// Name of the field in the .proto file.
+	Name string
+	// GoName of the struct field, promoted fields of embedded structs are accessed directly.
+	GoName string
+	Number int
+	Ref    *code.TypeRef
+}
+
+// builder collects messages of the interfaces and the declarations they reference.
+type builder struct {
+	messages []*message
+	// declared maps names of the messages to the package qualified names of their declarations
+	declared  map[string]string
+	timestamp bool
+}
+
+func newBuilder() *builder {
+	return &builder{declared: make(map[string]string, 0)}
+}
+
+// declare returns false if the message with the name has been already declared, message of another
+// package with the same name is an error since messages are named after the bare declaration names.
+func (b *builder) declare(pkg, name string) (bool, error) {
+	owner := pkg + "." + name
+	if declared, ok := b.declared[name]; ok {
+		if declared != owner {
+			return false, fmt.Errorf("protobuf: %s and %s are both declared as message %s", declared, owner, name)
+		}
+		return false, nil
+	}
+	b.declared[name] = owner
+	return true, nil
+}
+
+// build adds messages of the interfaces, referenced declarations are added after the ones referencing them.
+func (b *builder) build(ifaces code.InterfaceList) error {
+	queue := make([]func() error, 0)
+	for _, iface := range ifaces {
+		iface := iface
+		queue = append(queue, func() error { return b.iface(iface, &queue) })
+	}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if err := next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) iface(iface *code.Interface, queue *[]func() error) error {
+	if ok, err := b.declare(iface.Pkg, iface.Name); !ok {
+		return err
+	}
+	m := &message{Name: iface.Name, Interface: iface, Variants: iface.MappedVariants()}
+	b.messages = append(b.messages, m)
+	for _, v := range m.Variants {
+		if v.Struct == nil {
+			return fmt.Errorf("protobuf: variant %s of %s is not a struct", v.Name, iface.Name)
+		}
+		if err := b.object(v.Struct, queue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) object(s *code.Struct, queue *[]func() error) error {
+	if ok, err := b.declare(s.Pkg, s.Name); !ok {
+		return err
+	}
+	m := &message{Name: s.Name, Struct: s}
+	b.messages = append(b.messages, m)
+	numbered := make(map[int]string, 0)
+	for _, f := range s.JSONFields() {
+		if err := b.check(f.Field.Type, queue); err != nil {
+			return fmt.Errorf("protobuf: field %s of %s: %w", f.Field.Name, s.Name, err)
+		}
+		n, err := fieldNumber(f.Field)
+		if err != nil {
+			return fmt.Errorf("protobuf: field %s of %s: %w", f.Field.Name, s.Name, err)
+		}
+		if other, ok := numbered[n]; ok {
+			return fmt.Errorf("protobuf: fields %s and %s of %s have the same number %d", other, f.Field.Name, s.Name, n)
+		}
+		numbered[n] = f.Field.Name
+		m.Fields = append(m.Fields, &field{
+			Name:   fieldName(f.Name),
+			GoName: f.Field.Name,
+			Number: n,
+			Ref:    f.Field.Type,
+		})
+	}
+	return nil
+}
+
+// fieldNumber returns number of the field from its protobuf tag, i.e. `json:"name" protobuf:"1"`. Numbers aren't
+// derived from the declaration order, so that reordering the fields doesn't change the wire format.
+func fieldNumber(f *code.Field) (int, error) {
+	tag, ok := reflect.StructTag(strings.Trim(f.Tags, "`")).Lookup("protobuf")
+	if !ok {
+		return 0, fmt.Errorf("protobuf tag with the field number is required")
+	}
+	n, err := strconv.Atoi(tag)
+	if err != nil || !validNumber(n) {
+		return 0, fmt.Errorf("invalid field number %q", tag)
+	}
+	return n, nil
+}
+
+// validNumber is false for the numbers out of the protobuf range and the ones reserved by the implementation.
+func validNumber(n int) bool {
+	return n >= 1 && n <= 536870911 && (n < 19000 || n > 19999)
+}
+
+// check validates that the type can be represented in protobuf and queues the referenced declarations.
+func (b *builder) check(ref *code.TypeRef, queue *[]func() error) error {
+	if ref == nil {
+		return fmt.Errorf("unsupported type")
+	}
+	switch ref.Kind {
+	case code.RefPointer:
+		if ref.Elem == nil || ref.Elem.Kind != code.RefIdent || ref.Elem.Interface != nil {
+			return fmt.Errorf("unsupported pointer type")
+		}
+		return b.check(ref.Elem, queue)
+	case code.RefSlice, code.RefMap:
+		if ref.IsBytes() {
+			return nil
+		}
+		elem := deref(ref.Elem)
+		if elem == nil || elem.Kind != code.RefIdent || elem.IsBytes() {
+			return fmt.Errorf("unsupported nested collection")
+		}
+		return b.check(elem, queue)
+	case code.RefIdent:
+		switch {
+		case ref.Interface != nil:
+			iface := ref.Interface
+			*queue = append(*queue, func() error { return b.iface(iface, queue) })
+			return nil
+		case ref.Struct != nil:
+			s := ref.Struct
+			*queue = append(*queue, func() error { return b.object(s, queue) })
+			return nil
+		case isTime(ref):
+			b.timestamp = true
+			return nil
+		case isDuration(ref):
+			return nil
+		case scalar(ref) != "":
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported type %s", ref.Name)
+}
+
+// scalars maps predeclared Go types to the protobuf scalar types.
+var scalars = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int":     "int64",
+	"int8":    "int32",
+	"int16":   "int32",
+	"int32":   "int32",
+	"rune":    "int32",
+	"int64":   "int64",
+	"uint":    "uint64",
+	"uint8":   "uint32",
+	"byte":    "uint32",
+	"uint16":  "uint32",
+	"uint32":  "uint32",
+	"uint64":  "uint64",
+	"float32": "float",
+	"float64": "double",
+}
+
+// scalar returns protobuf scalar type of the predeclared type or the named type with predeclared underlying type,
+// empty if the type isn't a scalar.
+func scalar(ref *code.TypeRef) string {
+	return scalars[ref.Basic()]
+}
+
+// goScalars maps protobuf scalar types to the Go types generated by protoc-gen-go.
+var goScalars = map[string]string{
+	"string": "string",
+	"bool":   "bool",
+	"int32":  "int32",
+	"int64":  "int64",
+	"uint32": "uint32",
+	"uint64": "uint64",
+	"float":  "float32",
+	"double": "float64",
+}
+
+func deref(ref *code.TypeRef) *code.TypeRef {
+	if ref != nil && ref.Kind == code.RefPointer {
+		return ref.Elem
+	}
+	return ref
+}
+
+func isTime(ref *code.TypeRef) bool {
+	return ref.Kind == code.RefIdent && ref.Pkg == "time" && ref.Name == "Time"
+}
+
+func isDuration(ref *code.TypeRef) bool {
+	return ref.Kind == code.RefIdent && ref.Pkg == "time" && ref.Name == "Duration"
+}
+
+func isScalar(ref *code.TypeRef) bool {
+	return ref.Kind == code.RefIdent && ref.Interface == nil && ref.Struct == nil && !isTime(ref)
+}
+
+// fieldName replaces characters that are not allowed in protobuf identifiers with underscores.
+func fieldName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		b[i] = '_'
+	}
+	return string(b)
+}
+
+// snakeCase converts Go type name to the oneof field name, i.e. UserCreatedEvent -> user_created_event.
+func snakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// GoCamelCase converts protobuf name to the Go name the way protoc-gen-go does it.
+func GoCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
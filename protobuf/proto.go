@@ -0,0 +1,165 @@
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+// Options of the .proto file.
+type Options struct {
+	// Package of the .proto file, defaults to the name of the GoPackage.
+	Package string
+	// GoPackage is the go_package option, i.e. github.com/username/example/eventspb;eventspb.
+	GoPackage string
+	// Oneof are the numbers of the oneof fields by the names of the variants, see ParseNumbers.
+	Oneof map[string]int
+}
+
+// File returns .proto file declaring messages of the interfaces, their variants and the declarations they reference.
+// Fields are numbered by the protobuf tags of the struct fields and oneof fields by Options.Oneof, a field without
+// the number is an error, so that the wire format doesn't depend on the order of the declarations.
+func File(ifaces code.InterfaceList, opts *Options) (string, error) {
+	b := newBuilder()
+	if err := b.build(ifaces); err != nil {
+		return "", err
+	}
+
+	pkg := opts.Package
+	if pkg == "" && opts.GoPackage != "" {
+		_, pkg = GoImport(opts.GoPackage)
+	}
+	if pkg == "" {
+		return "", fmt.Errorf("protobuf: package or go_package option is required")
+	}
+	numbers, err := oneofNumbers(b.messages, opts.Oneof)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	sb.WriteString(fmt.Sprintf("package %s;\n", pkg))
+	if b.timestamp {
+		sb.WriteString("\nimport \"google/protobuf/timestamp.proto\";\n")
+	}
+	if opts.GoPackage != "" {
+		sb.WriteString(fmt.Sprintf("\noption go_package = %q;\n", opts.GoPackage))
+	}
+
+	for _, m := range b.messages {
+		sb.WriteString("\n")
+		if m.Interface != nil {
+			sb.WriteString(fmt.Sprintf("message %s {\n  oneof %s {\n", m.Name, OneofName))
+			for _, v := range m.Variants {
+				sb.WriteString(fmt.Sprintf("    %s %s = %d;\n", v.Name, snakeCase(v.Name), numbers[v.Name]))
+			}
+			sb.WriteString("  }\n}\n")
+			continue
+		}
+		if len(m.Fields) == 0 {
+			sb.WriteString(fmt.Sprintf("message %s {}\n", m.Name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("message %s {\n", m.Name))
+		for _, f := range m.Fields {
+			sb.WriteString(fmt.Sprintf("  %s %s = %d;\n", protoType(f.Ref), f.Name, f.Number))
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String(), nil
+}
+
+// oneofNumbers returns numbers of the oneof fields of the wrapper messages, every variant has to be numbered
+// and numbers of the variants of an interface have to be unique.
+func oneofNumbers(messages []*message, oneof map[string]int) (map[string]int, error) {
+	res := make(map[string]int, 0)
+	for _, m := range messages {
+		if m.Interface == nil {
+			continue
+		}
+		numbered := make(map[int]string, 0)
+		for _, v := range m.Variants {
+			n, ok := oneof[v.Name]
+			if !ok {
+				return nil, fmt.Errorf("protobuf: variant %s of %s has no oneof field number", v.Name, m.Name)
+			}
+			if !validNumber(n) {
+				return nil, fmt.Errorf("protobuf: variant %s of %s has invalid oneof field number %d", v.Name, m.Name, n)
+			}
+			if other, ok := numbered[n]; ok {
+				return nil, fmt.Errorf("protobuf: variants %s and %s of %s have the same number %d", other, v.Name, m.Name, n)
+			}
+			numbered[n] = v.Name
+			res[v.Name] = n
+		}
+	}
+	return res, nil
+}
+
+// ParseNumbers parses numbers of the oneof fields from the comma separated list of the variant names
+// with their numbers, i.e. BannedUser:1,PrivilegedUser:2.
+func ParseNumbers(s string) (map[string]int, error) {
+	res := make(map[string]int, 0)
+	if strings.TrimSpace(s) == "" {
+		return res, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, number, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("protobuf: invalid oneof field number %q, expected Variant:number", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil {
+			return nil, fmt.Errorf("protobuf: invalid oneof field number %q, expected Variant:number", pair)
+		}
+		res[strings.TrimSpace(name)] = n
+	}
+	return res, nil
+}
+
+// protoType returns type of the field with its label, i.e. repeated string.
+func protoType(ref *code.TypeRef) string {
+	switch ref.Kind {
+	case code.RefPointer:
+		if isScalar(ref.Elem) {
+			return "optional " + protoType(ref.Elem)
+		}
+		return protoType(ref.Elem)
+	case code.RefSlice:
+		if ref.IsBytes() {
+			return "bytes"
+		}
+		return "repeated " + protoType(deref(ref.Elem))
+	case code.RefMap:
+		return fmt.Sprintf("map<string, %s>", protoType(deref(ref.Elem)))
+	}
+	switch {
+	case ref.Interface != nil:
+		return ref.Interface.Name
+	case ref.Struct != nil:
+		return ref.Struct.Name
+	case isTime(ref):
+		return "google.protobuf.Timestamp"
+	case isDuration(ref):
+		return "int64"
+	default:
+		return scalar(ref)
+	}
+}
+
+// GoImport splits go_package option into the import path and the package name.
+func GoImport(goPackage string) (path, name string) {
+	path, name, ok := strings.Cut(goPackage, ";")
+	if !ok {
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+	return path, strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,198 @@
+package protobuf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/code"
+)
+
+const pkg = "github.com/eugenenosenko/gopoly/models"
+
+func events() code.InterfaceList {
+	contact := &code.Interface{Name: "Contact", Pkg: pkg}
+	contact.Variants = code.VariantList{
+		{Name: "EmailContact", Interface: contact, Struct: &code.Struct{
+			Pkg:  pkg,
+			Name: "EmailContact",
+			Fields: code.FieldList{
+				{Name: "Email", Tags: "`json:\"e-mail\" protobuf:\"1\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+			},
+		}},
+		{Name: "Unreachable", Interface: contact, Struct: &code.Struct{Name: "Unreachable", Pkg: pkg}},
+	}
+
+	address := &code.Struct{Name: "Address", Pkg: pkg, Fields: code.FieldList{
+		{Name: "City", Tags: "`json:\"city\" protobuf:\"1\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+	}}
+	event := &code.Interface{
+		Name:          "UserEvent",
+		Pkg:           pkg,
+		Discriminator: &code.Discriminator{Field: "type", Mapping: map[string]string{"CREATED": "UserCreatedEvent", "DELETED": "UserDeletedEvent"}},
+	}
+	event.Variants = code.VariantList{
+		{Name: "UserDeletedEvent", Interface: event, Struct: &code.Struct{
+			Pkg:  pkg,
+			Name: "UserDeletedEvent",
+			Fields: code.FieldList{
+				{Name: "Type", Tags: "`json:\"type\" protobuf:\"1\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "DeletedAt", Tags: "`json:\"deleted_at\" protobuf:\"2\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Time", Pkg: "time"}},
+			},
+		}},
+		{Name: "UserCreatedEvent", Interface: event, Struct: &code.Struct{
+			Pkg:  pkg,
+			Name: "UserCreatedEvent",
+			Fields: code.FieldList{
+				{Name: "Type", Tags: "`json:\"type\" protobuf:\"1\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "Age", Tags: "`json:\"age,omitempty\" protobuf:\"2\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "int"}},
+				{Name: "Score", Tags: "`json:\"score\" protobuf:\"3\"`", Type: &code.TypeRef{
+					Kind: code.RefPointer,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "float64"},
+				}},
+				{Name: "Contacts", Tags: "`json:\"contacts\" protobuf:\"4\"`", Type: &code.TypeRef{
+					Kind: code.RefSlice,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Contact", Interface: contact},
+				}},
+				{Name: "Address", Tags: "`json:\"address\" protobuf:\"5\"`", Type: &code.TypeRef{
+					Kind: code.RefPointer,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Address", Struct: address},
+				}},
+				{Name: "Labels", Tags: "`json:\"labels\" protobuf:\"6\"`", Type: &code.TypeRef{
+					Kind: code.RefMap,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "string"},
+				}},
+				{Name: "Status", Tags: "`json:\"status\" protobuf:\"8\"`", Type: &code.TypeRef{
+					Kind:       code.RefIdent,
+					Name:       "Status",
+					Underlying: "string",
+				}},
+				{Name: "Tags", Tags: "`json:\"tags\" protobuf:\"7\"`", Type: &code.TypeRef{
+					Kind: code.RefSlice,
+					Elem: &code.TypeRef{Kind: code.RefIdent, Name: "Tag", Pkg: "github.com/eugenenosenko/gopoly/tags", Underlying: "string"},
+				}},
+			},
+		}},
+	}
+	return code.InterfaceList{event, contact}
+}
+
+// oneof are the numbers of the oneof fields of events.
+var oneof = map[string]int{"UserCreatedEvent": 1, "UserDeletedEvent": 2, "EmailContact": 1, "Unreachable": 3}
+
+func TestFile(t *testing.T) {
+	t.Run("should declare wrapper messages with oneof of the variants", func(t *testing.T) {
+		got, err := File(events(), &Options{GoPackage: "github.com/eugenenosenko/gopoly/models/eventspb", Oneof: oneof})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile("testdata/events.golden.proto")
+		require.NoError(t, err)
+		require.Equal(t, string(data), got)
+	})
+	t.Run("should return error when package can't be resolved", func(t *testing.T) {
+		_, err := File(events(), &Options{})
+		require.EqualError(t, err, "protobuf: package or go_package option is required")
+	})
+	t.Run("should return error on unsupported field types", func(t *testing.T) {
+		iface := &code.Interface{Name: "Shape"}
+		iface.Variants = code.VariantList{{Name: "Grid", Interface: iface, Struct: &code.Struct{
+			Pkg:  pkg,
+			Name: "Grid",
+			Fields: code.FieldList{{Name: "Cells", Type: &code.TypeRef{
+				Kind: code.RefSlice,
+				Elem: &code.TypeRef{Kind: code.RefSlice, Elem: &code.TypeRef{Kind: code.RefIdent, Name: "int"}},
+			}}},
+		}}}
+		_, err := File(code.InterfaceList{iface}, &Options{Package: "shapes"})
+		require.EqualError(t, err, "protobuf: field Cells of Grid: unsupported nested collection")
+	})
+	t.Run("should return error when declarations of different packages have the same name", func(t *testing.T) {
+		order := &code.Interface{Name: "OrderEvent", Pkg: "github.com/eugenenosenko/gopoly/orders"}
+		order.Variants = code.VariantList{{Name: "Created", Interface: order, Struct: &code.Struct{
+			Pkg:  order.Pkg,
+			Name: "Created",
+		}}}
+		user := &code.Interface{Name: "UserEvent", Pkg: "github.com/eugenenosenko/gopoly/users"}
+		user.Variants = code.VariantList{{Name: "Created", Interface: user, Struct: &code.Struct{
+			Pkg:  user.Pkg,
+			Name: "Created",
+		}}}
+		_, err := File(code.InterfaceList{order, user}, &Options{Package: "events"})
+		require.EqualError(t, err, "protobuf: github.com/eugenenosenko/gopoly/orders.Created and "+
+			"github.com/eugenenosenko/gopoly/users.Created are both declared as message Created")
+	})
+	t.Run("should return error when field has no number", func(t *testing.T) {
+		ifaces := events()
+		ifaces[1].Variants[0].Struct.Fields[0].Tags = "`json:\"e-mail\"`"
+		_, err := File(ifaces, &Options{Package: "events", Oneof: oneof})
+		require.EqualError(t, err, "protobuf: field Email of EmailContact: protobuf tag with the field number is required")
+	})
+	t.Run("should return error on invalid field numbers", func(t *testing.T) {
+		ifaces := events()
+		ifaces[1].Variants[0].Struct.Fields[0].Tags = "`json:\"e-mail\" protobuf:\"19000\"`"
+		_, err := File(ifaces, &Options{Package: "events", Oneof: oneof})
+		require.EqualError(t, err, "protobuf: field Email of EmailContact: invalid field number \"19000\"")
+	})
+	t.Run("should return error when fields have the same number", func(t *testing.T) {
+		ifaces := events()
+		ifaces[0].Variants[0].Struct.Fields[1].Tags = "`json:\"deleted_at\" protobuf:\"1\"`"
+		_, err := File(ifaces, &Options{Package: "events", Oneof: oneof})
+		require.EqualError(t, err, "protobuf: fields Type and DeletedAt of UserDeletedEvent have the same number 1")
+	})
+	t.Run("should return error when variant has no oneof number", func(t *testing.T) {
+		_, err := File(events(), &Options{Package: "events", Oneof: map[string]int{"UserCreatedEvent": 1}})
+		require.EqualError(t, err, "protobuf: variant UserDeletedEvent of UserEvent has no oneof field number")
+	})
+	t.Run("should return error when variants have the same number", func(t *testing.T) {
+		_, err := File(events(), &Options{Package: "events", Oneof: map[string]int{
+			"UserCreatedEvent": 1, "UserDeletedEvent": 2, "EmailContact": 1, "Unreachable": 1,
+		}})
+		require.EqualError(t, err, "protobuf: variants EmailContact and Unreachable of Contact have the same number 1")
+	})
+}
+
+func TestParseNumbers(t *testing.T) {
+	t.Run("should parse numbers of the variants", func(t *testing.T) {
+		got, err := ParseNumbers("UserCreatedEvent:1, UserDeletedEvent:3")
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"UserCreatedEvent": 1, "UserDeletedEvent": 3}, got)
+	})
+	t.Run("should return error on invalid numbers", func(t *testing.T) {
+		_, err := ParseNumbers("UserCreatedEvent=1")
+		require.EqualError(t, err, "protobuf: invalid oneof field number \"UserCreatedEvent=1\", expected Variant:number")
+	})
+}
+
+func TestConversions(t *testing.T) {
+	t.Run("should convert variants to the oneof messages and back", func(t *testing.T) {
+		got, err := Conversions(events(), "github.com/eugenenosenko/gopoly/models/eventspb", func(path string) string {
+			return path[strings.LastIndex(path, "/")+1:]
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*Import{
+			{Alias: "eventspb", Path: "github.com/eugenenosenko/gopoly/models/eventspb"},
+			{Alias: "tags", Path: "github.com/eugenenosenko/gopoly/tags"},
+			{Alias: "timestamppb", Path: "google.golang.org/protobuf/types/known/timestamppb"},
+		}, got.Imports)
+
+		data, err := os.ReadFile("testdata/events.golden.go.txt")
+		require.NoError(t, err)
+		require.Equal(t, string(data), got.Code)
+	})
+}
+
+func TestGoCamelCase(t *testing.T) {
+	t.Run("should convert names the way protoc-gen-go does", func(t *testing.T) {
+		for in, want := range map[string]string{
+			"user_created_event": "UserCreatedEvent",
+			"deleted_at":         "DeletedAt",
+			"e_mail":             "EMail",
+			"_id":                "XId",
+			"value2":             "Value2",
+		} {
+			require.Equal(t, want, GoCamelCase(in), in)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+// Package scan finds the interfaces of Go packages that gopoly can generate decoders for, i.e. to create
+// the initial configuration.
+//
+// Interface is picked up when it declares a marker method, a method without parameters and results, that
+// is implemented by the types declared in the same package. Those types become the variants of the interface.
+package scan
+
+import (
+	"go/ast"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+// DiscriminatorKeys are the field names commonly used as discriminators, in the order of preference.
+var DiscriminatorKeys = []string{"type", "kind", "__typename", "@type", "_type", "$type", "typename", "discriminator"}
+
+// tagKeys are the struct tags that name the fields in the encoded payloads.
+var tagKeys = []string{"json", "yaml", "bson", "msgpack", "cbor", "xml"}
+
+// Interface is an interface found in the package along with its variants.
+type Interface struct {
+	// Package is the import path of the package declaring the interface.
+	Package      string
+	Name         string
+	MarkerMethod string
+	// Variants are the names of the types implementing the marker method, sorted.
+	Variants []string
+	// Discriminator is the field all the variants share, that is one of the DiscriminatorKeys. Empty when
+	// variants have no such field in common.
+	Discriminator string
+}
+
+// Packages loads the packages matching the patterns and returns the interfaces found in them, sorted by
+// their package and name.
+func Packages(patterns ...string) ([]*Interface, error) {
+	packs, err := packages.Load(
+		&packages.Config{
+			Mode: packages.NeedSyntax |
+				packages.NeedName |
+				packages.NeedCompiledGoFiles,
+		},
+		patterns...,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "scan.Packages: loading package path %v", patterns)
+	}
+
+	var err2 error
+	for _, pack := range packs {
+		for _, e := range pack.Errors {
+			err2 = multierr.Append(err2, e)
+		}
+	}
+	if err2 != nil {
+		return nil, errors.Wrapf(err2, "scan.Packages: reading package with path %v", patterns)
+	}
+
+	res := make([]*Interface, 0)
+	for _, pack := range packs {
+		res = append(res, scanFiles(pack.PkgPath, pack.Syntax)...)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Package != res[j].Package {
+			return res[i].Package < res[j].Package
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// Types returns type definitions of the interfaces found in the packages. Interfaces with the discriminator
+// field use discriminator decoding, discriminator values are guessed to be the variant names and are meant to be
+// reviewed. The rest is decoded with the strict decoding. Marker method is omitted when it's Is<Name>.
+func Types(ifaces []*Interface) config.TypesList {
+	res := make(config.TypesList, 0, len(ifaces))
+	for _, i := range ifaces {
+		def := &config.TypeDefinition{
+			Name:             i.Name,
+			Variants:         i.Variants,
+			DecodingStrategy: config.DecodingStrategyStrict,
+			Package:          i.Package,
+		}
+		if i.MarkerMethod != "Is"+i.Name {
+			def.MarkerMethod = i.MarkerMethod
+		}
+		if i.Discriminator != "" {
+			mapping := make(map[string]string, len(i.Variants))
+			for _, v := range i.Variants {
+				mapping[v] = v
+			}
+			def.DecodingStrategy = config.DecodingStrategyDiscriminator
+			def.Discriminator = config.DiscriminatorDefinition{Field: i.Discriminator, Mapping: mapping}
+		}
+		res = append(res, def)
+	}
+	return res
+}
+
+func scanFiles(pkg string, files []*ast.File) []*Interface {
+	ifaces := make(map[string]*ast.InterfaceType, 0)
+	types := make(map[string]ast.Expr, 0)
+	// names of the types implementing marker methods by the method name
+	implementors := make(map[string][]string, 0)
+	for _, f := range files {
+		for _, dec := range f.Decls {
+			switch d := dec.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					if it, ok := ts.Type.(*ast.InterfaceType); ok {
+						ifaces[ts.Name.Name] = it
+					} else {
+						types[ts.Name.Name] = ts.Type
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 || !isMarker(d.Type) {
+					continue
+				}
+				if name := receiverName(d.Recv.List[0].Type); name != "" {
+					implementors[d.Name.Name] = append(implementors[d.Name.Name], name)
+				}
+			}
+		}
+	}
+
+	res := make([]*Interface, 0)
+	for name, it := range ifaces {
+		marker, variants := markerMethod(name, it, implementors, types)
+		if marker == "" {
+			continue
+		}
+		res = append(res, &Interface{
+			Package:       pkg,
+			Name:          name,
+			MarkerMethod:  marker,
+			Variants:      variants,
+			Discriminator: discriminator(variants, types),
+		})
+	}
+	return res
+}
+
+// markerMethod returns the marker method of the interface and the types implementing it. Is<Name> is
+// preferred when interface has several marker methods, the first one declared is used otherwise.
+func markerMethod(
+	name string,
+	it *ast.InterfaceType,
+	implementors map[string][]string,
+	types map[string]ast.Expr,
+) (string, []string) {
+	var marker string
+	var variants []string
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 || !isMarker(ft) {
+			continue
+		}
+		method := m.Names[0].Name
+		vv := declared(implementors[method], types)
+		if len(vv) == 0 {
+			continue
+		}
+		if marker == "" || method == "Is"+name {
+			marker, variants = method, vv
+		}
+	}
+	return marker, variants
+}
+
+// declared returns sorted unique names that are declared as non-interface types.
+func declared(names []string, types map[string]ast.Expr) []string {
+	set := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		if _, ok := types[n]; ok {
+			set[n] = struct{}{}
+		}
+	}
+	res := make([]string, 0, len(set))
+	for n := range set {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func isMarker(ft *ast.FuncType) bool {
+	return ft.Params.NumFields() == 0 && ft.Results.NumFields() == 0
+}
+
+func receiverName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	default:
+		return ""
+	}
+}
+
+// discriminator returns the first of the DiscriminatorKeys that names a field of every variant.
+func discriminator(variants []string, types map[string]ast.Expr) string {
+	fields := make([]map[string]struct{}, 0, len(variants))
+	for _, v := range variants {
+		st, ok := types[v].(*ast.StructType)
+		if !ok {
+			return ""
+		}
+		fields = append(fields, fieldNames(st))
+	}
+	for _, key := range DiscriminatorKeys {
+		shared := true
+		for _, names := range fields {
+			if _, ok := names[key]; !ok {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			return key
+		}
+	}
+	return ""
+}
+
+// fieldNames returns the names of the struct fields in the encoded payloads, taken from the struct tags
+// or the lower-cased field name when field has no tags.
+func fieldNames(st *ast.StructType) map[string]struct{} {
+	res := make(map[string]struct{}, 0)
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
+		}
+		var tagged bool
+		for _, key := range tagKeys {
+			if v, ok := tag.Lookup(key); ok {
+				name, _, _ := strings.Cut(v, ",")
+				if name != "" && name != "-" {
+					res[name] = struct{}{}
+					tagged = true
+				}
+			}
+		}
+		if tagged {
+			continue
+		}
+		for _, n := range f.Names {
+			res[strings.ToLower(n.Name)] = struct{}{}
+		}
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/eugenenosenko/gopoly/config"
+)
+
+func TestPackages(t *testing.T) {
+	t.Run("should find interfaces with marker methods implemented in the package", func(t *testing.T) {
+		ifaces, err := Packages("./testdata/models")
+		require.NoError(t, err)
+
+		require.Equal(t, []*Interface{
+			{
+				Package:       "github.com/eugenenosenko/gopoly/scan/testdata/models",
+				Name:          "Event",
+				MarkerMethod:  "IsEvent",
+				Variants:      []string{"CreatedEvent", "DeletedEvent"},
+				Discriminator: "type",
+			},
+			{
+				Package:      "github.com/eugenenosenko/gopoly/scan/testdata/models",
+				Name:         "Shape",
+				MarkerMethod: "shape",
+				Variants:     []string{"Circle", "Square"},
+			},
+		}, ifaces)
+	})
+
+	t.Run("should fail on packages that can't be loaded", func(t *testing.T) {
+		_, err := Packages("./testdata/missing")
+		require.Error(t, err)
+	})
+}
+
+func TestTypes(t *testing.T) {
+	t.Run("should guess discriminator mapping and omit default marker methods", func(t *testing.T) {
+		types := Types([]*Interface{
+			{
+				Package:       "github.com/username/example/events",
+				Name:          "Event",
+				MarkerMethod:  "IsEvent",
+				Variants:      []string{"Created", "Deleted"},
+				Discriminator: "type",
+			},
+			{
+				Package:      "github.com/username/example/shapes",
+				Name:         "Shape",
+				MarkerMethod: "shape",
+				Variants:     []string{"Circle", "Square"},
+			},
+		})
+
+		require.Equal(t, config.TypesList{
+			{
+				Name:             "Event",
+				Variants:         []string{"Created", "Deleted"},
+				DecodingStrategy: config.DecodingStrategyDiscriminator,
+				Discriminator: config.DiscriminatorDefinition{
+					Field:   "type",
+					Mapping: map[string]string{"Created": "Created", "Deleted": "Deleted"},
+				},
+				Package: "github.com/username/example/events",
+			},
+			{
+				Name:             "Shape",
+				Variants:         []string{"Circle", "Square"},
+				MarkerMethod:     "shape",
+				DecodingStrategy: config.DecodingStrategyStrict,
+				Package:          "github.com/username/example/shapes",
+			},
+		}, types)
+	})
+}
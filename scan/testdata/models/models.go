@@ -0,0 +1,51 @@
+package models
+
+type Event interface {
+	IsEvent()
+	ID() string
+}
+
+type CreatedEvent struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func (CreatedEvent) IsEvent() {}
+
+func (e CreatedEvent) ID() string { return e.Name }
+
+type DeletedEvent struct {
+	Type string `json:"type,omitempty" bson:"type"`
+}
+
+func (*DeletedEvent) IsEvent() {}
+
+func (e *DeletedEvent) ID() string { return "" }
+
+// Shape has no discriminator shared by the variants.
+type Shape interface {
+	shape()
+}
+
+type Circle struct {
+	Kind   string
+	Radius float64
+}
+
+func (Circle) shape() {}
+
+type Square struct {
+	Side float64 `json:"side"`
+}
+
+func (Square) shape() {}
+
+// Named has no marker methods.
+type Named interface {
+	Name() string
+}
+
+// Empty has no implementors.
+type Empty interface {
+	IsEmpty()
+}
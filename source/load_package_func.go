@@ -6,19 +6,26 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"golang.org/x/tools/go/packages"
+
+	"github.com/eugenenosenko/gopoly/internal/xmod"
 )
 
 type Package struct {
-	Name  string
-	Path  string
+	Name string
+	Path string
+	// Dir is the directory of the package, generated files of the package are written there.
+	Dir   string
 	Files []*ast.File
 }
 
+// LoadFromPackage loads the packages with the go command run in the working directory, so that packages of all
+// the modules of the go.work workspace, or the one set by GOWORK, are found.
 func LoadFromPackage(paths ...string) ([]*Package, error) {
 	packs, err := packages.Load(
 		&packages.Config{
 			Mode: packages.NeedSyntax |
 				packages.NeedName |
+				packages.NeedFiles |
 				packages.NeedCompiledGoFiles,
 		},
 		paths...,
@@ -42,6 +49,7 @@ func LoadFromPackage(paths ...string) ([]*Package, error) {
 		res = append(res, &Package{
 			Name:  pack.Name,
 			Path:  pack.PkgPath,
+			Dir:   xmod.PackageDir(pack),
 			Files: pack.Syntax,
 		})
 	}
@@ -1,6 +1,8 @@
 package source
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -25,4 +27,23 @@ func TestLoadFromPackage(t *testing.T) {
 		require.Equal(t, "github.com/eugenenosenko/gopoly/source/testdata/c", got[2].Path)
 		require.Len(t, got[2].Files, 1)
 	})
+
+	t.Run("should load packages of the modules of the go.work workspace", func(t *testing.T) {
+		dir, err := filepath.Abs("testdata/workspace")
+		require.NoError(t, err)
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+		// -mod flags can't be used in workspace mode
+		t.Setenv("GOFLAGS", "")
+		got, err := LoadFromPackage("example.com/events", "example.com/shapes")
+
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		require.Equal(t, "example.com/events", got[0].Path)
+		require.Equal(t, filepath.Join(dir, "events"), got[0].Dir)
+		require.Equal(t, "example.com/shapes", got[1].Path)
+		require.Equal(t, filepath.Join(dir, "shapes"), got[1].Dir)
+	})
 }
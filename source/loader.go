@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
+	"go/types"
 	"path"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -33,6 +35,7 @@ type Config struct {
 
 type Definition struct {
 	Dec     ast.Decl
+	File    *ast.File
 	PkgName string
 	PkgPath string
 }
@@ -67,9 +70,13 @@ func (l *loader) Load(ctx context.Context, c []*config.TypeDefinition) (code.Sou
 		return nil, errors.Wrapf(err, "collecting interfaces from %v", packageNames)
 	}
 
+	dirs := make(map[PkgPath]string, len(files))
+	for _, f := range files {
+		dirs[f.Path] = f.Dir
+	}
 	psources := make(map[PkgPath]*code.Source, 0)
 	for pkg, i := range ifaces {
-		psources[pkg] = &code.Source{Package: code.Package(pkg), Interfaces: i}
+		psources[pkg] = &code.Source{Package: code.Package(pkg), Dir: dirs[pkg], Interfaces: i}
 	}
 
 	for pkg, decs := range pdecs {
@@ -108,6 +115,21 @@ func (l *loader) Load(ctx context.Context, c []*config.TypeDefinition) (code.Sou
 			}
 		}
 	}
+	// declarations are collected concurrently, sort imports to keep generated code stable
+	for _, source := range psources {
+		sort.Slice(source.Imports, func(i, j int) bool {
+			a, b := source.Imports[i], source.Imports[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return a.ShortName < b.ShortName
+		})
+	}
+
+	structs, err := declareStructs(pdecs, ifaces)
+	if err != nil {
+		return nil, errors.Wrapf(err, "collecting structs from %v", packageNames)
+	}
 
 	for pkg, decs := range pdecs {
 		types := xslices.Flatten(xslices.Map[[]*code.Interface, [][]*code.Variant](
@@ -139,6 +161,7 @@ func (l *loader) Load(ctx context.Context, c []*config.TypeDefinition) (code.Sou
 				if !ok {
 					continue
 				}
+				variant.Struct = structs[pkg][ts.Name.Name]
 
 				pfields := make([]*code.PolyField, 0)
 				for _, field := range strct.Fields.List {
@@ -190,6 +213,190 @@ func (l *loader) Load(ctx context.Context, c []*config.TypeDefinition) (code.Sou
 	return maps.Values(psources), nil
 }
 
+// declareStructs collects all struct declarations of the packages and resolves types of their fields
+// to the structs and configured interfaces.
+func declareStructs(
+	pdecs map[PkgPath][]*Definition,
+	ifaces map[PkgPath]code.InterfaceList,
+) (map[PkgPath]map[string]*code.Struct, error) {
+	pstructs := make(map[PkgPath]map[string]*code.Struct, 0)
+	for pkg, decs := range pdecs {
+		pstructs[pkg] = make(map[string]*code.Struct, 0)
+		for _, ts := range structSpecs(decs) {
+			pstructs[pkg][ts.Name.Name] = &code.Struct{Name: ts.Name.Name, Pkg: pkg}
+		}
+	}
+
+	r := &refResolver{
+		structs: pstructs,
+		ifaces:  make(map[PkgPath]map[string]*code.Interface, 0),
+		basics:  basicTypes(pdecs),
+	}
+	for pkg, ii := range ifaces {
+		r.ifaces[pkg] = ii.AssociateByName()
+	}
+	fimports := make(map[*ast.File]map[string]string, 0)
+	for pkg, decs := range pdecs {
+		for _, dec := range decs {
+			imports, ok := fimports[dec.File]
+			if !ok {
+				var err error
+				if imports, err = fileImports(dec.File); err != nil {
+					return nil, err
+				}
+				fimports[dec.File] = imports
+			}
+			for _, ts := range structSpecs([]*Definition{dec}) {
+				s := pstructs[pkg][ts.Name.Name]
+				s.Fields = make(code.FieldList, 0)
+				for _, field := range ts.Type.(*ast.StructType).Fields.List {
+					ref := r.typeRef(field.Type, pkg, imports)
+					var tags string
+					if field.Tag != nil {
+						tags = field.Tag.Value
+					}
+					if len(field.Names) == 0 { // embedded field is named after its type
+						s.Fields = append(s.Fields, &code.Field{Name: embeddedName(ref), Tags: tags, Type: ref, Embedded: true})
+						continue
+					}
+					for _, name := range field.Names {
+						s.Fields = append(s.Fields, &code.Field{Name: name.Name, Tags: tags, Type: ref})
+					}
+				}
+			}
+		}
+	}
+	return pstructs, nil
+}
+
+func structSpecs(decs []*Definition) []*ast.TypeSpec {
+	var res []*ast.TypeSpec
+	for _, ts := range typeSpecs(decs) {
+		if _, ok := ts.Type.(*ast.StructType); ok {
+			res = append(res, ts)
+		}
+	}
+	return res
+}
+
+func typeSpecs(decs []*Definition) []*ast.TypeSpec {
+	var res []*ast.TypeSpec
+	for _, dec := range decs {
+		d, ok := dec.Dec.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range d.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				res = append(res, ts)
+			}
+		}
+	}
+	return res
+}
+
+// basicTypes returns predeclared underlying types of the named basic types of the packages by their names,
+// following the declarations of the same package, i.e. string for type Kind Name and type Name string.
+func basicTypes(pdecs map[PkgPath][]*Definition) map[PkgPath]map[string]string {
+	res := make(map[PkgPath]map[string]string, 0)
+	for pkg, decs := range pdecs {
+		idents := make(map[string]string, 0)
+		for _, ts := range typeSpecs(decs) {
+			if ident, ok := ts.Type.(*ast.Ident); ok {
+				idents[ts.Name.Name] = ident.Name
+			}
+		}
+		res[pkg] = make(map[string]string, 0)
+		for name, u := range idents {
+			// bounded by the number of declarations in case of the invalid cyclic ones
+			for i := 0; i < len(idents); i++ {
+				next, ok := idents[u]
+				if !ok {
+					break
+				}
+				u = next
+			}
+			if _, declared := idents[u]; !declared && isBasic(u) {
+				res[pkg][name] = u
+			}
+		}
+	}
+	return res
+}
+
+func isBasic(name string) bool {
+	if o, ok := types.Universe.Lookup(name).(*types.TypeName); ok {
+		_, basic := o.Type().(*types.Basic)
+		return basic
+	}
+	return false
+}
+
+// fileImports returns import paths of the file by their short name or alias.
+func fileImports(f *ast.File) (map[string]string, error) {
+	res := make(map[string]string, 0)
+	if f == nil {
+		return res, nil
+	}
+	for _, i := range f.Imports {
+		importpath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		sname := path.Base(importpath)
+		if alias := i.Name; alias != nil {
+			sname = alias.Name
+		}
+		res[sname] = importpath
+	}
+	return res, nil
+}
+
+type refResolver struct {
+	structs map[PkgPath]map[string]*code.Struct
+	ifaces  map[PkgPath]map[string]*code.Interface
+	// basics are the underlying predeclared types of the named basic types
+	basics map[PkgPath]map[string]string
+}
+
+func (r *refResolver) typeRef(e ast.Expr, pkg PkgPath, imports map[string]string) *code.TypeRef {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return &code.TypeRef{
+			Kind:       code.RefIdent,
+			Name:       t.Name,
+			Struct:     r.structs[pkg][t.Name],
+			Interface:  r.ifaces[pkg][t.Name],
+			Underlying: r.basics[pkg][t.Name],
+		}
+	case *ast.SelectorExpr:
+		importpath := imports[importPrefix(t.X)]
+		return &code.TypeRef{
+			Kind:       code.RefIdent,
+			Name:       t.Sel.Name,
+			Pkg:        importpath,
+			Struct:     r.structs[importpath][t.Sel.Name],
+			Interface:  r.ifaces[importpath][t.Sel.Name],
+			Underlying: r.basics[importpath][t.Sel.Name],
+		}
+	case *ast.StarExpr:
+		return &code.TypeRef{Kind: code.RefPointer, Elem: r.typeRef(t.X, pkg, imports)}
+	case *ast.ArrayType:
+		return &code.TypeRef{Kind: code.RefSlice, Elem: r.typeRef(t.Elt, pkg, imports)}
+	case *ast.MapType:
+		return &code.TypeRef{Kind: code.RefMap, Elem: r.typeRef(t.Value, pkg, imports)}
+	default:
+		return &code.TypeRef{Kind: code.RefOther}
+	}
+}
+
+func embeddedName(ref *code.TypeRef) string {
+	if ref.Kind == code.RefPointer {
+		return embeddedName(ref.Elem)
+	}
+	return ref.Name
+}
+
 func importPrefix(e ast.Expr) string {
 	if ident, ok := e.(*ast.Ident); ok {
 		return ident.Name // short name or alias
@@ -227,6 +434,9 @@ func interfaces(defs map[PkgPath][]*Definition, tts []*config.TypeDefinition) (m
 			Variants:     []*code.Variant{},
 			Pkg:          t.Package,
 		}
+		if t.DecodingStrategy.IsDiscriminator() {
+			i.Discriminator = &code.Discriminator{Field: t.Discriminator.Field, Mapping: t.Discriminator.Mapping}
+		}
 
 		for _, dec := range defs[t.Package] {
 			// check only functions and methods
@@ -245,6 +455,7 @@ func interfaces(defs map[PkgPath][]*Definition, tts []*config.TypeDefinition) (m
 			}
 			// fetch the name of the receiver type
 			var name string
+			var pointer bool
 			if ident, ok := receiver.Type.(*ast.Ident); ok {
 				name = ident.Name
 			} else if star, ok := receiver.Type.(*ast.StarExpr); ok {
@@ -252,17 +463,17 @@ func interfaces(defs map[PkgPath][]*Definition, tts []*config.TypeDefinition) (m
 					continue
 				}
 				if i, ok := star.X.(*ast.Ident); ok {
-					name = i.Name
+					name, pointer = i.Name, true
 				}
 			}
 
 			// if discriminator check whether the receiver type is defined as a variant of the i-face
 			if t.DecodingStrategy.IsDiscriminator() {
 				if _, ok := expected[name]; ok {
-					i.Variants = append(i.Variants, &code.Variant{Name: name, Interface: i})
+					i.Variants = append(i.Variants, &code.Variant{Name: name, Interface: i, PointerReceiver: pointer})
 				}
 			} else {
-				i.Variants = append(i.Variants, &code.Variant{Name: name, Interface: i})
+				i.Variants = append(i.Variants, &code.Variant{Name: name, Interface: i, PointerReceiver: pointer})
 			}
 		}
 
@@ -385,7 +596,7 @@ func declarations(ctx context.Context, packs []*Package) <-chan *Definition {
 							select {
 							case <-ctx.Done():
 								return
-							case c <- &Definition{Dec: d, PkgName: p.Name, PkgPath: p.Path}:
+							case c <- &Definition{Dec: d, File: f, PkgName: p.Name, PkgPath: p.Path}:
 							}
 						}
 					}(file, p)
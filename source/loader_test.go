@@ -2,6 +2,7 @@ package source
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,8 @@ import (
 
 func TestLoad(t *testing.T) {
 	t.Run("should correctly load source from packages", func(t *testing.T) {
+		dir, err := filepath.Abs("testdata")
+		require.NoError(t, err)
 		l, err := NewLoader(&Config{
 			Logf:     func(_ string, _ ...any) {},
 			LoadFunc: LoadFromPackage,
@@ -40,13 +43,39 @@ func TestLoad(t *testing.T) {
 				Interface: runner,
 				Kind:      code.KindScalar,
 			},
-		}, Interface: runner}
-		b := &code.Variant{Name: "SlowRunner", Fields: code.PolyFieldList{}, Interface: runner}
+		}, Interface: runner, PointerReceiver: true}
+		b := &code.Variant{Name: "SlowRunner", Fields: code.PolyFieldList{}, Interface: runner, PointerReceiver: true}
 
+		a.Struct = &code.Struct{
+			Name: "FastRunner",
+			Pkg:  "github.com/eugenenosenko/gopoly/source/testdata",
+			Fields: code.FieldList{
+				{Name: "Name", Tags: "`json:\"name\"`", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "Runner", Tags: "`json:\"runner\"`", Type: &code.TypeRef{
+					Kind:      code.RefIdent,
+					Name:      "Runner",
+					Interface: runner,
+				}},
+				{Name: "C", Tags: "`json:\"c\"`", Type: &code.TypeRef{
+					Kind: code.RefIdent,
+					Name: "C",
+					Pkg:  "github.com/eugenenosenko/gopoly/source/testdata/c",
+				}},
+			},
+		}
+		b.Struct = &code.Struct{
+			Name: "SlowRunner",
+			Pkg:  "github.com/eugenenosenko/gopoly/source/testdata",
+			Fields: code.FieldList{
+				{Name: "Name", Type: &code.TypeRef{Kind: code.RefIdent, Name: "string"}},
+				{Name: "Pace", Type: &code.TypeRef{Kind: code.RefIdent, Name: "Pace", Underlying: "float64"}},
+			},
+		}
 		runner.Variants = code.VariantList{a, b}
 		want := code.SourceList{
 			{
 				Package:    "github.com/eugenenosenko/gopoly/source/testdata",
+				Dir:        dir,
 				Interfaces: code.InterfaceList{runner},
 				Imports:    nil,
 			},
@@ -18,6 +18,11 @@ func (a *FastRunner) IsRunner() {}
 
 type SlowRunner struct {
 	Name string
+	Pace Pace
 }
 
+type Pace Speed
+
+type Speed float64
+
 func (a *SlowRunner) IsRunner() {}
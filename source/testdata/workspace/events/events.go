@@ -0,0 +1,14 @@
+package events
+
+import "example.com/shapes"
+
+type Event interface {
+	IsEvent()
+}
+
+type DrawnEvent struct {
+	Type  string       `json:"type"`
+	Shape shapes.Shape `json:"shape"`
+}
+
+func (DrawnEvent) IsEvent() {}
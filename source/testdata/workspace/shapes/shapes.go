@@ -0,0 +1,11 @@
+package shapes
+
+type Shape interface {
+	IsShape()
+}
+
+type Square struct {
+	Side int `json:"side"`
+}
+
+func (Square) IsShape() {}
@@ -68,3 +68,14 @@ func TestLookUpImport(t *testing.T) {
 		assert.Equal(t, `"github.com/eugenenosenko/gopoly/internal/models"`, strings.TrimSpace(got))
 	})
 }
+
+func TestJSONName(t *testing.T) {
+	t.Run("should take field name from json tag", func(t *testing.T) {
+		assert.Equal(t, "runner", jsonName(code.PolyField{Name: "Runner", Tags: "`json:\"runner,omitempty\"`"}))
+	})
+
+	t.Run("should fall back to the name of the field", func(t *testing.T) {
+		assert.Equal(t, "Runner", jsonName(code.PolyField{Name: "Runner", Tags: "`yaml:\"runner\"`"}))
+		assert.Equal(t, "Runner", jsonName(code.PolyField{Name: "Runner"}))
+	})
+}
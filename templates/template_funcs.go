@@ -1,15 +1,23 @@
 package templates
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
+	"github.com/pkg/errors"
 	"golang.org/x/exp/maps"
 
 	"github.com/eugenenosenko/gopoly/code"
 	"github.com/eugenenosenko/gopoly/codegen"
+	"github.com/eugenenosenko/gopoly/graphql"
 	"github.com/eugenenosenko/gopoly/internal/xslices"
+	"github.com/eugenenosenko/gopoly/jsonschema"
+	"github.com/eugenenosenko/gopoly/protobuf"
+	"github.com/eugenenosenko/gopoly/typescript"
 )
 
 func DefaultFuncs() template.FuncMap {
@@ -17,6 +25,21 @@ func DefaultFuncs() template.FuncMap {
 		"dedupTypes":    dedupTypes,
 		"prefixed":      prefixedField,
 		"lookupImports": lookupImports,
+		"jsonName":      jsonName,
+		"msgpackName":   msgpackName,
+		"cborName":      cborName,
+		"bsonName":      bsonName,
+		"xmlAttribute":  xmlAttribute,
+		"xmlType":       xmlType,
+		"hasRegistry":   hasRegistry,
+		"hasSQLColumn":  hasSQLColumn,
+		"variantTags":   variantTags,
+		"jsonSchema":    jsonSchema,
+		"typeScript":    typeScript,
+		"graphQL":       graphQL,
+		"protoFile":     protoFile,
+		"protoImports":  protoImports,
+		"protoConvert":  protoConvert,
 		"upper":         strings.ToUpper,
 		"lower":         strings.ToLower,
 	}
@@ -44,9 +67,14 @@ func lookupImports(d *codegen.Input) string {
 		return ""
 	}
 
+	imports := maps.Values(iis)
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Path < imports[j].Path
+	})
+
 	var sb strings.Builder
 	sb.WriteString("\n\n")
-	for _, ii := range iis {
+	for _, ii := range imports {
 		sb.WriteString("\t")
 		if ii.Aliased {
 			sb.WriteString(ii.ShortName)
@@ -58,15 +86,60 @@ func lookupImports(d *codegen.Input) string {
 	return sb.String()
 }
 
+// hasRegistry reports whether any of the codegen.Type in the Input is backed by a variant registry.
+func hasRegistry(d *codegen.Input) bool {
+	for _, t := range d.Types {
+		if t.Registry {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSQLColumn reports whether any of the codegen.Type in the Input has a generated SQL column type.
+func hasSQLColumn(d *codegen.Input) bool {
+	for _, t := range d.Types {
+		if t.SQLColumn {
+			return true
+		}
+	}
+	return false
+}
+
+// variantTag is a discriminator tag of the variant.
+type variantTag struct {
+	Tag     string
+	Variant *code.Variant
+}
+
+// variantTags returns a discriminator tag of every variant of the type sorted by variant name. When multiple
+// tags are mapped to the same variant the lowest one is used.
+func variantTags(t *codegen.Type) []variantTag {
+	tags := make(map[string]string, len(t.Variants))
+	for tag, v := range t.Variants {
+		if cur, ok := tags[v.Name]; !ok || tag < cur {
+			tags[v.Name] = tag
+		}
+	}
+	res := make([]variantTag, 0, len(tags))
+	for _, v := range dedupTypes(t.Variants) {
+		res = append(res, variantTag{Tag: tags[v.Name], Variant: v})
+	}
+	return res
+}
+
 // dedupTypes filters out duplicated variants.
 // User can define multiple discriminator mappings that match to same type.
 // Dedup is required in order to not re-define Unmarshal method for the same code.Variant type.
+// Variants are sorted by name so that generated code is stable between runs.
 func dedupTypes(vars map[string]*code.Variant) []*code.Variant {
 	variants := xslices.ToMap[[]*code.Variant, map[string]*code.Variant](
 		maps.Values(vars), func(v *code.Variant) string {
 			return v.Name
 		}, nil)
-	return maps.Values(variants)
+	res := maps.Values(variants)
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
 }
 
 // prefixedField checks whether code.PolyField has an import-prefix and if it has one
@@ -77,3 +150,192 @@ func prefixedField(f code.PolyField) string {
 	}
 	return ""
 }
+
+// jsonName returns the name of the code.PolyField as it appears in the JSON payload. Name is taken from
+// the json struct tag and if it's missing falls back to the name of the field.
+func jsonName(f code.PolyField) string {
+	return tagName(f, "json")
+}
+
+// msgpackName returns the name of the code.PolyField as it appears in the MessagePack payload. Name is taken
+// from the msgpack struct tag and if it's missing falls back to the name of the field.
+func msgpackName(f code.PolyField) string {
+	return tagName(f, "msgpack")
+}
+
+// cborName returns the name of the code.PolyField as it appears in the CBOR payload. Name is taken from
+// the cbor struct tag, then from the json struct tag and if both are missing falls back to the name of the field.
+func cborName(f code.PolyField) string {
+	return tagName(f, "cbor", "json")
+}
+
+// bsonName returns the key of the code.PolyField in the BSON document. Name is taken from the bson struct tag
+// and if it's missing falls back to the lowercased name of the field, the way the mongo driver does it.
+func bsonName(f code.PolyField) string {
+	tag := reflect.StructTag(strings.Trim(f.Tags, "`")).Get("bson")
+	if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(f.Name)
+}
+
+// xmlNamespaces are the namespaces of the well-known attribute prefixes.
+var xmlNamespaces = map[string]string{
+	"xsi": "http://www.w3.org/2001/XMLSchema-instance",
+}
+
+// xmlAttr is the attribute selecting variants of the xml target.
+type xmlAttr struct {
+	// Name of the attribute as configured, i.e. xsi:type.
+	Name   string
+	Prefix string
+	// Space is the namespace of the prefix if it's well-known.
+	Space string
+	Local string
+	// QName reports whether the value is a qualified name with a prefix that has to be stripped.
+	QName bool
+}
+
+// xmlAttribute returns the attribute selecting variants configured by the xml target options, nil when variants are
+// selected by the element name.
+func xmlAttribute(d *codegen.Input) *xmlAttr {
+	name := d.Options["attribute"]
+	if name == "" {
+		return nil
+	}
+	prefix, local, ok := strings.Cut(name, ":")
+	if !ok {
+		return &xmlAttr{Name: name, Local: name}
+	}
+	space := xmlNamespaces[prefix]
+	return &xmlAttr{
+		Name:   name,
+		Prefix: prefix,
+		Space:  space,
+		Local:  local,
+		QName:  space != "" && local == "type",
+	}
+}
+
+// xmlType pairs the codegen.Type with the attribute selecting its variants.
+func xmlType(t *codegen.Type, attr *xmlAttr) any {
+	return struct {
+		Type      *codegen.Type
+		Attribute *xmlAttr
+	}{Type: t, Attribute: attr}
+}
+
+func tagName(f code.PolyField, keys ...string) string {
+	tags := reflect.StructTag(strings.Trim(f.Tags, "`"))
+	for _, key := range keys {
+		if name, _, _ := strings.Cut(tags.Get(key), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// jsonSchema returns indented JSON Schema document of the type. Document describes exactly one type.
+func jsonSchema(d *codegen.Input) (string, error) {
+	if len(d.Types) != 1 {
+		return "", fmt.Errorf("JSON schema document describes exactly one type, got %d, use {{ .Name }} in the filename", len(d.Types))
+	}
+	t := d.Types[0]
+	if t.Interface == nil {
+		return "", fmt.Errorf("missing declaration of %s", t.Name)
+	}
+	doc, err := jsonschema.Document(t.Interface)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "encoding %s JSON schema", t.Name)
+	}
+	return string(b), nil
+}
+
+// typeScript returns TypeScript declarations of the types.
+func typeScript(d *codegen.Input) (string, error) {
+	ifaces, err := interfaces(d)
+	if err != nil {
+		return "", err
+	}
+	return typescript.Declarations(ifaces)
+}
+
+// graphQL returns GraphQL SDL of the types.
+func graphQL(d *codegen.Input) (string, error) {
+	ifaces, err := interfaces(d)
+	if err != nil {
+		return "", err
+	}
+	return graphql.Schema(ifaces)
+}
+
+// protoFile returns .proto file with the messages of the types.
+func protoFile(d *codegen.Input) (string, error) {
+	ifaces, err := interfaces(d)
+	if err != nil {
+		return "", err
+	}
+	oneof, err := protobuf.ParseNumbers(d.Options["oneof"])
+	if err != nil {
+		return "", err
+	}
+	return protobuf.File(ifaces, &protobuf.Options{
+		Package:   d.Options["package"],
+		GoPackage: d.Options["go_package"],
+		Oneof:     oneof,
+	})
+}
+
+// protoImports returns imports of the functions converting the types to the protobuf messages.
+func protoImports(d *codegen.Input) (string, error) {
+	src, err := protoSource(d)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, i := range src.Imports {
+		sb.WriteString(fmt.Sprintf("\t%s %q\n", i.Alias, i.Path))
+	}
+	return sb.String(), nil
+}
+
+// protoConvert returns functions converting the types to the protobuf messages and back.
+func protoConvert(d *codegen.Input) (string, error) {
+	src, err := protoSource(d)
+	if err != nil {
+		return "", err
+	}
+	return src.Code, nil
+}
+
+func protoSource(d *codegen.Input) (*protobuf.GoSource, error) {
+	ifaces, err := interfaces(d)
+	if err != nil {
+		return nil, err
+	}
+	imports := code.ImportList(d.Imports)
+	return protobuf.Conversions(ifaces, d.Options["go_package"], func(path string) string {
+		for _, i := range imports {
+			if i.Path == path {
+				return i.ShortName
+			}
+		}
+		return path[strings.LastIndex(path, "/")+1:]
+	})
+}
+
+// interfaces returns source declarations of the types.
+func interfaces(d *codegen.Input) (code.InterfaceList, error) {
+	res := make(code.InterfaceList, 0, len(d.Types))
+	for _, t := range d.Types {
+		if t.Interface == nil {
+			return nil, fmt.Errorf("missing declaration of %s", t.Name)
+		}
+		res = append(res, t.Interface)
+	}
+	return res, nil
+}
@@ -10,3 +10,76 @@ var defaultJSONTemplate string
 func DefaultJSONTemplate() string {
 	return defaultJSONTemplate
 }
+
+//go:embed jsonschema.gotpl
+var jsonSchemaTemplate string
+
+//go:embed typescript.gotpl
+var typeScriptTemplate string
+
+// TypeScriptTemplate renders TypeScript declarations of the types of the codegen.Input.
+func TypeScriptTemplate() string {
+	return typeScriptTemplate
+}
+
+//go:embed graphql.gotpl
+var graphQLTemplate string
+
+// GraphQLTemplate renders GraphQL SDL of the types of the codegen.Input.
+func GraphQLTemplate() string {
+	return graphQLTemplate
+}
+
+//go:embed msgpack.gotpl
+var msgpackTemplate string
+
+// MsgPackTemplate renders MessagePack decoders of the types of the codegen.Input.
+func MsgPackTemplate() string {
+	return msgpackTemplate
+}
+
+//go:embed cbor.gotpl
+var cborTemplate string
+
+// CBORTemplate renders CBOR decoders of the types of the codegen.Input.
+func CBORTemplate() string {
+	return cborTemplate
+}
+
+//go:embed bson.gotpl
+var bsonTemplate string
+
+// BSONTemplate renders BSON decoders of the types of the codegen.Input.
+func BSONTemplate() string {
+	return bsonTemplate
+}
+
+//go:embed xml.gotpl
+var xmlTemplate string
+
+// XMLTemplate renders XML decoders of the types of the codegen.Input.
+func XMLTemplate() string {
+	return xmlTemplate
+}
+
+//go:embed proto.gotpl
+var protoTemplate string
+
+// ProtoTemplate renders .proto file with messages of the types of the codegen.Input.
+func ProtoTemplate() string {
+	return protoTemplate
+}
+
+//go:embed proto_conversions.gotpl
+var protoConversionsTemplate string
+
+// ProtoConversionsTemplate renders Go functions converting the types of the codegen.Input to the protobuf
+// messages and back.
+func ProtoConversionsTemplate() string {
+	return protoConversionsTemplate
+}
+
+// JSONSchemaTemplate renders JSON Schema document of the single type of the codegen.Input.
+func JSONSchemaTemplate() string {
+	return jsonSchemaTemplate
+}
@@ -6,13 +6,26 @@
 package e2e
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/eugenenosenko/gopoly/polyerr"
+	"github.com/eugenenosenko/gopoly/tests/e2e/testdata/devices"
+	"github.com/eugenenosenko/gopoly/tests/e2e/testdata/documents"
 	"github.com/eugenenosenko/gopoly/tests/e2e/testdata/events"
 	"github.com/eugenenosenko/gopoly/tests/e2e/testdata/users"
+	"github.com/eugenenosenko/gopoly/tests/e2e/testdata/users/userspb"
 )
 
 func TestE2E(t *testing.T) {
@@ -51,4 +64,412 @@ func TestE2E(t *testing.T) {
 			},
 		}, event)
 	})
+	t.Run("should report location of the unknown variant", func(t *testing.T) {
+		_, err := events.UnmarshalUserEventJSON([]byte(`{"id":"1","type":"DELETED","user":{"id":"2","kind":"ADMIN"}}`))
+
+		var uv *polyerr.UnknownVariantError
+		require.True(t, errors.As(err, &uv))
+		require.Equal(t, "User", uv.Interface)
+		require.Equal(t, "ADMIN", uv.Value)
+		require.Equal(t, "/user", uv.Path)
+	})
+
+	t.Run("should report location of the element that doesn't match any variant", func(t *testing.T) {
+		_, err := events.UnmarshalUserEventJSON([]byte(
+			`{"id":"1","type":"DELETED","user":{"id":"2","kind":"REGULAR","contacts":[{"id":"1","business_name":"B"},{"fax":"2"}]}}`,
+		))
+
+		var nm *polyerr.NoMatchError
+		require.True(t, errors.As(err, &nm))
+		require.Equal(t, "Contact", nm.Interface)
+		require.Equal(t, "/user/contacts/1", nm.Path)
+	})
+	t.Run("should unmarshal variants registered at run-time", func(t *testing.T) {
+		events.RegisterOrderEventVariant("REFUNDED", func() events.OrderEvent { return &orderRefundedEvent{} })
+
+		event, err := events.UnmarshalOrderEventJSON([]byte(`{"id":"1","type":"REFUNDED","amount":100}`))
+		require.NoError(t, err)
+		require.Equal(t, &orderRefundedEvent{ID: "1", Type: "REFUNDED", Amount: 100}, event)
+	})
+}
+
+func TestE2EStream(t *testing.T) {
+	t.Run("should decode polymorphic elements of NDJSON stream one at a time", func(t *testing.T) {
+		s := events.NewUserEventStream(strings.NewReader(
+			`{"id":"1","type":"CREATED","user":{"id":"1","kind":"BANNED","ban_reason":"spam"}}
+			{"id":"2","type":"DELETED","user":null}`,
+		))
+
+		var got []events.UserEvent
+		for s.Next() {
+			got = append(got, s.Value())
+		}
+		require.NoError(t, s.Err())
+		require.Equal(t, []events.UserEvent{
+			&events.UserCreatedEvent{
+				ID:   "1",
+				Type: "CREATED",
+				User: &users.BannedUser{ID: "1", Type: "BANNED", Contacts: []users.Contact{}, BanReason: "spam"},
+			},
+			&events.UserDeletedEvent{ID: "2", Type: "DELETED"},
+		}, got)
+	})
+
+	t.Run("should report index of the failed element of JSON array", func(t *testing.T) {
+		s := events.NewUserEventStream(strings.NewReader(`[{"id":"1","type":"CREATED"},{"id":"2","type":"UPDATED"}]`))
+
+		require.True(t, s.Next())
+		require.False(t, s.Next())
+		require.Equal(t, "/1", polyerr.Pointer(s.Err()))
+	})
+}
+
+type userEventCounter struct{ created, deleted int }
+
+func (c *userEventCounter) VisitUserCreatedEvent(_ *events.UserCreatedEvent) error {
+	c.created++
+	return nil
+}
+
+func (c *userEventCounter) VisitUserDeletedEvent(_ *events.UserDeletedEvent) error {
+	c.deleted++
+	return nil
+}
+
+func TestE2EVisitor(t *testing.T) {
+	t.Run("should dispatch variants to the visitor", func(t *testing.T) {
+		var c userEventCounter
+		require.NoError(t, events.AcceptUserEvent(&events.UserCreatedEvent{}, &c))
+		require.NoError(t, events.AcceptUserEvent(events.UserDeletedEvent{}, &c))
+		require.NoError(t, events.AcceptUserEvent(&events.UserDeletedEvent{}, &c))
+
+		require.Equal(t, userEventCounter{created: 1, deleted: 2}, c)
+	})
+
+	t.Run("should call callback matching the variant", func(t *testing.T) {
+		var got string
+		err := users.MatchContact(&users.PrivateContact{ID: "2"},
+			func(c *users.BusinessContact) error { got = "business " + c.ID; return nil },
+			func(c *users.PrivateContact) error { got = "private " + c.ID; return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, "private 2", got)
+	})
+
+	t.Run("should reject unknown variants", func(t *testing.T) {
+		err := events.AcceptOrderEvent(&orderRefundedEvent{}, nil)
+
+		var uv *polyerr.UnknownVariantError
+		require.True(t, errors.As(err, &uv))
+		require.Equal(t, "*e2e.orderRefundedEvent", uv.Value)
+	})
+}
+
+type orderRefundedEvent struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+}
+
+func (e orderRefundedEvent) IsOrderEvent() {}
+
+func TestE2EJSONSchema(t *testing.T) {
+	t.Run("should generate JSON schema document of the configured interface", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/schemas/User.schema.gen.json")
+		require.NoError(t, err)
+
+		var schema struct {
+			Title         string `json:"title"`
+			OneOf         []any  `json:"oneOf"`
+			Discriminator struct {
+				PropertyName string            `json:"propertyName"`
+				Mapping      map[string]string `json:"mapping"`
+			} `json:"discriminator"`
+			Defs map[string]json.RawMessage `json:"$defs"`
+		}
+		require.NoError(t, json.Unmarshal(data, &schema))
+
+		require.Equal(t, "User", schema.Title)
+		require.Len(t, schema.OneOf, 3)
+		require.Equal(t, "kind", schema.Discriminator.PropertyName)
+		require.Equal(t, "#/$defs/BannedUser", schema.Discriminator.Mapping["BANNED"])
+		require.Contains(t, schema.Defs, "Contact")
+		require.Contains(t, schema.Defs, "BusinessContact")
+	})
+}
+
+func TestE2EOpenAPI(t *testing.T) {
+	t.Run("should export components schemas of configured interfaces", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/openapi.gen.json")
+		require.NoError(t, err)
+
+		var doc struct {
+			OpenAPI    string `json:"openapi"`
+			Components struct {
+				Schemas map[string]struct {
+					OneOf         []map[string]string `json:"oneOf"`
+					Discriminator struct {
+						PropertyName string            `json:"propertyName"`
+						Mapping      map[string]string `json:"mapping"`
+					} `json:"discriminator"`
+				} `json:"schemas"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(data, &doc))
+
+		require.Equal(t, "3.1.0", doc.OpenAPI)
+		event := doc.Components.Schemas["UserEvent"]
+		require.Equal(t, "type", event.Discriminator.PropertyName)
+		require.Equal(t, map[string]string{
+			"CREATED": "#/components/schemas/UserCreatedEvent",
+			"DELETED": "#/components/schemas/UserDeletedEvent",
+		}, event.Discriminator.Mapping)
+		for _, name := range []string{"OrderEvent", "Order", "Contact", "User", "UserDeletedEvent", "RegularUser"} {
+			require.Contains(t, doc.Components.Schemas, name)
+		}
+	})
+}
+
+func TestE2ETypeScript(t *testing.T) {
+	t.Run("should declare discriminated unions of the types sharing the file", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/ts/events.gen.ts")
+		require.NoError(t, err)
+
+		ts := string(data)
+		require.Contains(t, ts, "export type UserEvent = UserCreatedEvent | UserDeletedEvent;")
+		require.Contains(t, ts, "export type OrderEvent = OrderCancelledEvent | OrderCompletedEvent;")
+		require.Contains(t, ts, "export type User = BannedUser | PrivilegedUser | RegularUser;")
+		require.Contains(t, ts, "export interface UserDeletedEvent {\n  type: \"DELETED\";\n")
+		require.Equal(t, 1, strings.Count(ts, "export type Contact ="))
+	})
+}
+
+func TestE2EGraphQL(t *testing.T) {
+	t.Run("should declare unions and object types of the configured interface", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/schemas/users.gen.graphql")
+		require.NoError(t, err)
+
+		sdl := string(data)
+		require.Contains(t, sdl, "union User = BannedUser | PrivilegedUser | RegularUser\n")
+		require.Contains(t, sdl, "union Contact = BusinessContact | PrivateContact\n")
+		require.Contains(t, sdl, "type PrivilegedUser {\n  id: String!\n  kind: String!\n")
+		require.Contains(t, sdl, "  contacts: [Contact!]!\n")
+	})
+}
+
+func TestE2EProto(t *testing.T) {
+	t.Run("should declare wrapper messages with oneof of the variants", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/schemas/users.gen.proto")
+		require.NoError(t, err)
+
+		file := string(data)
+		require.Contains(t, file, "package users.v1;\n")
+		require.Contains(t, file, "message User {\n  oneof value {\n    BannedUser banned_user = 3;\n")
+		require.Contains(t, file, "message Contact {\n  oneof value {\n")
+		require.Contains(t, file, "  repeated Contact contacts = 5;\n")
+	})
+	t.Run("should convert variants to the messages and back", func(t *testing.T) {
+		user := &users.RegularUser{
+			ID:      "1234",
+			Type:    "REGULAR",
+			Name:    "John Doe",
+			Address: "Kings Road 12, London, UK",
+			Contacts: []users.Contact{
+				&users.BusinessContact{ID: "1", BusinessName: "Business Ltd.", Phone: "0 800 122 222"},
+				&users.PrivateContact{ID: "2", FullName: users.FullName{Firstname: "John", Lastname: "Doe"}},
+			},
+		}
+		m, err := users.UserToProto(user)
+		require.NoError(t, err)
+		data, err := proto.Marshal(m)
+		require.NoError(t, err)
+
+		decoded := &userspb.User{}
+		require.NoError(t, proto.Unmarshal(data, decoded))
+		got, err := users.UserFromProto(decoded)
+		require.NoError(t, err)
+		require.Equal(t, user, got)
+	})
+	t.Run("should convert empty oneof to nil interface", func(t *testing.T) {
+		got, err := users.UserFromProto(&userspb.User{Value: &userspb.User_BannedUser{}})
+		require.NoError(t, err)
+		require.Nil(t, got)
+	})
+}
+
+func TestE2EMsgPack(t *testing.T) {
+	batch := &devices.BatchReading{
+		Kind:     "BATCH",
+		DeviceID: "sensor-1",
+		Readings: []devices.Reading{
+			&devices.TemperatureReading{Kind: "TEMPERATURE", Celsius: 21.5},
+			&devices.HumidityReading{Kind: "HUMIDITY", Percent: 40},
+		},
+	}
+	t.Run("should decode variants selected by the discriminator of the map", func(t *testing.T) {
+		data, err := msgpack.Marshal(batch)
+		require.NoError(t, err)
+
+		reading, err := devices.UnmarshalReadingMsgPack(data)
+		require.NoError(t, err)
+		require.Equal(t, batch, reading)
+	})
+	t.Run("should decode consecutive values of the decoder", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		require.NoError(t, enc.Encode(batch))
+		require.NoError(t, enc.Encode(batch.Readings[0]))
+
+		dec := msgpack.NewDecoder(&buf)
+		first, err := devices.DecodeReadingMsgPack(dec)
+		require.NoError(t, err)
+		require.Equal(t, batch, first)
+
+		second, err := devices.DecodeReadingMsgPack(dec)
+		require.NoError(t, err)
+		require.Equal(t, batch.Readings[0], second)
+
+		_, err = devices.DecodeReadingMsgPack(dec)
+		var decodeErr *polyerr.DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		require.ErrorIs(t, err, io.EOF)
+	})
+	t.Run("should return path of the unknown variant", func(t *testing.T) {
+		data, err := msgpack.Marshal(map[string]any{
+			"kind":     "BATCH",
+			"readings": []any{map[string]any{"kind": "PRESSURE"}},
+		})
+		require.NoError(t, err)
+
+		_, err = devices.UnmarshalReadingMsgPack(data)
+		var unknown *polyerr.UnknownVariantError
+		require.ErrorAs(t, err, &unknown)
+		require.Equal(t, "PRESSURE", unknown.Value)
+		require.Equal(t, "/readings/0", unknown.Path)
+	})
+}
+
+func TestE2ECBOR(t *testing.T) {
+	batch := &devices.BatchReading{
+		Kind:     "BATCH",
+		DeviceID: "sensor-1",
+		Readings: []devices.Reading{
+			&devices.TemperatureReading{Kind: "TEMPERATURE", Celsius: 21.5},
+			&devices.HumidityReading{Kind: "HUMIDITY", Percent: 40},
+		},
+	}
+	t.Run("should decode variants selected by the discriminator of the map", func(t *testing.T) {
+		data, err := cbor.Marshal(batch)
+		require.NoError(t, err)
+
+		reading, err := devices.UnmarshalReadingCBOR(data)
+		require.NoError(t, err)
+		require.Equal(t, batch, reading)
+	})
+	t.Run("should decode consecutive values of the decoder", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := cbor.NewEncoder(&buf)
+		require.NoError(t, enc.Encode(batch))
+		require.NoError(t, enc.Encode(batch.Readings[1]))
+
+		dec := cbor.NewDecoder(&buf)
+		first, err := devices.DecodeReadingCBOR(dec)
+		require.NoError(t, err)
+		require.Equal(t, batch, first)
+
+		second, err := devices.DecodeReadingCBOR(dec)
+		require.NoError(t, err)
+		require.Equal(t, batch.Readings[1], second)
+
+		_, err = devices.DecodeReadingCBOR(dec)
+		var decodeErr *polyerr.DecodeError
+		require.ErrorAs(t, err, &decodeErr)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestE2EBSON(t *testing.T) {
+	t.Run("should decode variants selected by the discriminator of the document", func(t *testing.T) {
+		batch := &devices.BatchReading{
+			Kind:     "BATCH",
+			DeviceID: "sensor-1",
+			Readings: []devices.Reading{
+				&devices.TemperatureReading{Kind: "TEMPERATURE", Celsius: 21.5},
+				&devices.HumidityReading{Kind: "HUMIDITY", Percent: 40},
+			},
+		}
+		data, err := bson.Marshal(batch)
+		require.NoError(t, err)
+
+		reading, err := devices.UnmarshalReadingBSON(data)
+		require.NoError(t, err)
+		require.Equal(t, batch, reading)
+	})
+	t.Run("should decode missing and null polymorphic fields as nil", func(t *testing.T) {
+		data, err := bson.Marshal(bson.D{{Key: "kind", Value: "BATCH"}, {Key: "readings", Value: bson.A{nil}}})
+		require.NoError(t, err)
+
+		reading, err := devices.UnmarshalReadingBSON(data)
+		require.NoError(t, err)
+		require.Equal(t, &devices.BatchReading{Kind: "BATCH", Readings: []devices.Reading{nil}}, reading)
+	})
+}
+
+func TestE2EXML(t *testing.T) {
+	t.Run("should decode variants selected by the element name", func(t *testing.T) {
+		shape, err := documents.UnmarshalShapeXML([]byte(`
+			<group name="logo">
+				<circle radius="2"/>
+				<group name="inner"><square side="3"/></group>
+			</group>`))
+		require.NoError(t, err)
+		require.Equal(t, &documents.Group{
+			Name: "logo",
+			Shapes: []documents.Shape{
+				&documents.Circle{Radius: 2},
+				&documents.Group{Name: "inner", Shapes: []documents.Shape{&documents.Square{Side: 3}}},
+			},
+		}, shape)
+	})
+	t.Run("should decode variants selected by the xsi:type attribute", func(t *testing.T) {
+		payment, err := documents.UnmarshalPaymentXML([]byte(`
+			<payment xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:p="urn:payments" xsi:type="p:Refund">
+				<amount>10</amount>
+				<original xsi:type="CardPayment"><amount>10</amount><card>4242</card></original>
+			</payment>`))
+		require.NoError(t, err)
+		require.Equal(t, &documents.Refund{
+			Amount:   10,
+			Original: &documents.CardPayment{Amount: 10, Card: "4242"},
+		}, payment)
+	})
+	t.Run("should return path of the unknown variant", func(t *testing.T) {
+		_, err := documents.UnmarshalShapeXML([]byte(`<group><triangle/></group>`))
+		var unknown *polyerr.UnknownVariantError
+		require.ErrorAs(t, err, &unknown)
+		require.Equal(t, "triangle", unknown.Value)
+		require.Equal(t, "/triangle", unknown.Path)
+	})
+}
+
+func TestE2ESQLColumn(t *testing.T) {
+	t.Run("should store variant with its discriminator and scan it back", func(t *testing.T) {
+		user := &users.RegularUser{ID: "1", Type: "REGULAR", Name: "John Doe", Contacts: []users.Contact{}}
+		value, err := events.UserEventColumn{UserEvent: &events.UserDeletedEvent{ID: "2", User: user}}.Value()
+		require.NoError(t, err)
+		require.Contains(t, value, `"type":"DELETED"`)
+
+		var column events.UserEventColumn
+		require.NoError(t, column.Scan([]byte(value.(string))))
+		require.Equal(t, &events.UserDeletedEvent{ID: "2", Type: "DELETED", User: user}, column.UserEvent)
+	})
+	t.Run("should store nil interface as NULL", func(t *testing.T) {
+		value, err := events.UserEventColumn{}.Value()
+		require.NoError(t, err)
+		require.Nil(t, value)
+
+		column := events.UserEventColumn{UserEvent: &events.UserCreatedEvent{}}
+		require.NoError(t, column.Scan(nil))
+		require.Nil(t, column.UserEvent)
+	})
 }
@@ -1,3 +1,7 @@
 go build -o gopoly -v ../../main.go
 chmod +x ./gopoly
-./gopoly -c testdata/.gopoly.yaml
+./gopoly generate -c testdata/.gopoly.yaml
+go run -tags e2e ./scripts/protogen -module github.com/eugenenosenko/gopoly/tests/e2e testdata/schemas/users.gen.proto
+./gopoly check -c testdata/.gopoly.yaml
+./gopoly lint -c testdata/.gopoly.yaml ./testdata/devices ./testdata/documents ./testdata/events ./testdata/orders ./testdata/users
+./gopoly openapi -c testdata/.gopoly.yaml -o testdata/openapi.gen.json
@@ -0,0 +1,91 @@
+//go:build e2e
+// +build e2e
+
+// Command protogen compiles .proto files and generates their Go code with protoc-gen-go, so that the e2e tests
+// don't depend on protoc being installed:
+//
+//	go run -tags e2e ./scripts/protogen -module github.com/eugenenosenko/gopoly/tests/e2e testdata/schemas/users.gen.proto
+//
+// Files are resolved relative to the working directory, generated code is written to the directory of the
+// go_package with the module prefix stripped.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/bufbuild/protocompile"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	module := flag.String("module", "", "module prefix stripped from the go_package of the generated files")
+	flag.Parse()
+	if err := run(context.Background(), *module, flag.Args()); err != nil {
+		log.Fatalf("protogen: %v", err)
+	}
+}
+
+func run(ctx context.Context, module string, files []string) error {
+	c := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"."}}),
+	}
+	compiled, err := c.Compile(ctx, files...)
+	if err != nil {
+		return err
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{FileToGenerate: files}
+	if module != "" {
+		req.Parameter = proto.String("module=" + module)
+	}
+	seen := make(map[string]struct{}, 0)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if _, ok := seen[fd.Path()]; ok {
+			return
+		}
+		seen[fd.Path()] = struct{}{}
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		// dependencies go first, as protoc sends them
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, f := range compiled {
+		add(f)
+	}
+
+	plugin, err := protogen.Options{}.New(req)
+	if err != nil {
+		return err
+	}
+	for _, f := range plugin.Files {
+		if f.Generate {
+			gengo.GenerateFile(plugin, f)
+		}
+	}
+	res := plugin.Response()
+	if res.Error != nil {
+		return fmt.Errorf("%s", res.GetError())
+	}
+	for _, f := range res.File {
+		if err := os.MkdirAll(filepath.Dir(f.GetName()), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.GetName(), []byte(f.GetContent()), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
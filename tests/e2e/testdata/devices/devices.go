@@ -0,0 +1,27 @@
+package devices
+
+type Reading interface {
+	IsReading()
+}
+
+type TemperatureReading struct {
+	Kind    string  `json:"kind" msgpack:"kind" cbor:"kind" bson:"kind"`
+	Celsius float64 `json:"celsius" msgpack:"celsius" cbor:"celsius" bson:"celsius"`
+}
+
+func (r TemperatureReading) IsReading() {}
+
+type HumidityReading struct {
+	Kind    string  `json:"kind" msgpack:"kind" cbor:"kind" bson:"kind"`
+	Percent float64 `json:"percent" msgpack:"percent" cbor:"percent" bson:"percent"`
+}
+
+func (r HumidityReading) IsReading() {}
+
+type BatchReading struct {
+	Kind     string    `json:"kind" msgpack:"kind" cbor:"kind" bson:"kind"`
+	DeviceID string    `json:"device_id" msgpack:"device_id" cbor:"device_id" bson:"device_id"`
+	Readings []Reading `json:"readings" msgpack:"readings" cbor:"readings" bson:"readings"`
+}
+
+func (r BatchReading) IsReading() {}
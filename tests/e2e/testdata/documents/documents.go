@@ -0,0 +1,48 @@
+package documents
+
+type Shape interface {
+	IsShape()
+}
+
+type Circle struct {
+	Radius float64 `xml:"radius,attr"`
+}
+
+func (s Circle) IsShape() {}
+
+type Square struct {
+	Side float64 `xml:"side,attr"`
+}
+
+func (s Square) IsShape() {}
+
+type Group struct {
+	Name   string  `xml:"name,attr"`
+	Shapes []Shape `xml:",any"`
+}
+
+func (s Group) IsShape() {}
+
+type Payment interface {
+	IsPayment()
+}
+
+type CardPayment struct {
+	Amount int    `xml:"amount"`
+	Card   string `xml:"card"`
+}
+
+func (p CardPayment) IsPayment() {}
+
+type CashPayment struct {
+	Amount int `xml:"amount"`
+}
+
+func (p CashPayment) IsPayment() {}
+
+type Refund struct {
+	Amount   int     `xml:"amount"`
+	Original Payment `xml:"original"`
+}
+
+func (p Refund) IsPayment() {}
@@ -8,55 +8,58 @@ type Contact interface {
 	IsContact()
 }
 
+// Kind is the discriminator of the users.
+type Kind string
+
 type RegularUser struct {
-	ID       string    `json:"id"`
-	Type     string    `json:"kind"`
-	Name     string    `json:"name"`
-	Address  string    `json:"address"`
-	Contacts []Contact `json:"contacts"`
+	ID       string    `json:"id" protobuf:"1"`
+	Type     Kind      `json:"kind" protobuf:"2"`
+	Name     string    `json:"name" protobuf:"3"`
+	Address  string    `json:"address" protobuf:"4"`
+	Contacts []Contact `json:"contacts" protobuf:"5"`
 }
 
 func (a RegularUser) IsUser() {}
 
 type PrivilegedUser struct {
-	ID         string    `json:"id"`
-	Type       string    `json:"kind"`
-	Name       string    `json:"name"`
-	Address    string    `json:"address"`
-	Contacts   []Contact `json:"contacts"`
-	Privileges []string  `json:"privileges"`
+	ID         string    `json:"id" protobuf:"1"`
+	Type       Kind      `json:"kind" protobuf:"2"`
+	Name       string    `json:"name" protobuf:"3"`
+	Address    string    `json:"address" protobuf:"4"`
+	Contacts   []Contact `json:"contacts" protobuf:"5"`
+	Privileges []string  `json:"privileges" protobuf:"6"`
 }
 
 func (a PrivilegedUser) IsUser() {}
 
 type BannedUser struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"kind"`
-	Contacts  []Contact `json:"contacts"`
-	BanReason string    `json:"ban_reason"`
+	ID        string    `json:"id" protobuf:"1"`
+	Type      Kind      `json:"kind" protobuf:"2"`
+	Contacts  []Contact `json:"contacts" protobuf:"3"`
+	BanReason string    `json:"ban_reason" protobuf:"4"`
 }
 
 func (o BannedUser) IsUser() {}
 
 type BusinessContact struct {
-	ID           string `json:"id"`
-	BusinessName string `json:"business_name"`
-	Phone        string `json:"phone"`
-	Email        string `json:"email"`
+	ID           string `json:"id" protobuf:"1"`
+	BusinessName string `json:"business_name" protobuf:"2"`
+	Phone        string `json:"phone" protobuf:"3"`
+	Email        string `json:"email" protobuf:"4"`
 }
 
 func (c BusinessContact) IsContact() {}
 
 type FullName struct {
-	Firstname string `json:"firstname"`
-	Lastname  string `json:"lastname"`
+	Firstname string `json:"firstname" protobuf:"1"`
+	Lastname  string `json:"lastname" protobuf:"2"`
 }
 
 type PrivateContact struct {
-	ID       string   `json:"id"`
-	FullName FullName `json:"fullname"`
-	Phone    string   `json:"phone"`
-	Email    string   `json:"email"`
+	ID       string   `json:"id" protobuf:"1"`
+	FullName FullName `json:"fullname" protobuf:"2"`
+	Phone    string   `json:"phone" protobuf:"3"`
+	Email    string   `json:"email" protobuf:"4"`
 }
 
 func (c PrivateContact) IsContact() {}
@@ -0,0 +1,31 @@
+export type UserEvent = UserCreatedEvent | UserDeletedEvent;
+
+export interface UserCreatedEvent {
+  type: "CREATED" | "NEW";
+  age?: number | null;
+  contacts: Contact[];
+  addresses: Record<string, Address | null>;
+  tags: (string | null)[];
+  avatar: string;
+  extra: unknown;
+  status: string;
+}
+
+export interface UserDeletedEvent {
+  type: "DELETED";
+  deleted_at: string;
+}
+
+export type Contact = EmailContact | PhoneContact;
+
+export interface EmailContact {
+  "e-mail": string;
+}
+
+export interface PhoneContact {
+  phone: string;
+}
+
+export interface Address {
+  city: string;
+}
@@ -0,0 +1,175 @@
+// Package typescript renders TypeScript declarations of the configured interfaces. Interface is declared as
+// a union of its variants, variants of the discriminated interfaces have literal types of their discriminator
+// fields, so that TypeScript can narrow the union:
+//
+//	export type UserEvent = UserCreatedEvent | UserDeletedEvent;
+//
+//	export interface UserCreatedEvent {
+//	  type: "CREATED";
+//	  user: User;
+//	}
+package typescript
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eugenenosenko/gopoly/code"
+	"github.com/eugenenosenko/gopoly/internal/decls"
+)
+
+// Declarations returns TypeScript declarations of the interfaces, their variants and the declarations they reference.
+// Returns error when declarations of different packages have the same name.
+func Declarations(ifaces code.InterfaceList) (string, error) {
+	r := &renderer{decls: decls.New("typescript")}
+	for _, iface := range ifaces {
+		r.iface(iface)
+	}
+	if err := r.decls.Flush(); err != nil {
+		return "", err
+	}
+	return r.sb.String(), nil
+}
+
+type renderer struct {
+	sb    strings.Builder
+	decls *decls.Set
+}
+
+func (r *renderer) iface(iface *code.Interface) {
+	if !r.decls.Declare(iface.Pkg, iface.Name) {
+		return
+	}
+	variants := iface.MappedVariants()
+	names := make([]string, 0, len(variants))
+	for _, v := range variants {
+		names = append(names, v.Name)
+	}
+	if len(names) == 0 {
+		names = append(names, "never")
+	}
+
+	if r.sb.Len() > 0 {
+		r.sb.WriteString("\n")
+	}
+	r.sb.WriteString(fmt.Sprintf("export type %s = %s;\n", iface.Name, strings.Join(names, " | ")))
+	for _, v := range variants {
+		r.variant(iface, v)
+	}
+}
+
+func (r *renderer) variant(iface *code.Interface, v *code.Variant) {
+	if !r.decls.Declare(iface.Pkg, v.Name) {
+		return
+	}
+	var discriminator *code.JSONField
+	fields := v.Struct.JSONFields()
+	if d := iface.Discriminator; d != nil {
+		discriminator = &code.JSONField{Name: d.Field}
+		for i, f := range fields {
+			if f.Name == d.Field {
+				fields = append(fields[:i:i], fields[i+1:]...)
+				break
+			}
+		}
+	}
+
+	r.sb.WriteString(fmt.Sprintf("\nexport interface %s {\n", v.Name))
+	if discriminator != nil {
+		tags := iface.Discriminator.Tags(v.Name)
+		literals := make([]string, 0, len(tags))
+		for _, t := range tags {
+			literals = append(literals, fmt.Sprintf("%q", t))
+		}
+		r.sb.WriteString(fmt.Sprintf("  %s: %s;\n", property(discriminator.Name), strings.Join(literals, " | ")))
+	}
+	r.fields(fields)
+	r.sb.WriteString("}\n")
+}
+
+func (r *renderer) object(s *code.Struct) {
+	r.sb.WriteString(fmt.Sprintf("\nexport interface %s {\n", s.Name))
+	r.fields(s.JSONFields())
+	r.sb.WriteString("}\n")
+}
+
+func (r *renderer) fields(fields []*code.JSONField) {
+	for _, f := range fields {
+		optional := ""
+		if f.OmitEmpty {
+			optional = "?"
+		}
+		r.sb.WriteString(fmt.Sprintf("  %s%s: %s;\n", property(f.Name), optional, r.typeRef(f.Field.Type)))
+	}
+}
+
+func (r *renderer) typeRef(ref *code.TypeRef) string {
+	if ref == nil {
+		return "unknown"
+	}
+	switch ref.Kind {
+	case code.RefPointer:
+		return r.typeRef(ref.Elem) + " | null"
+	case code.RefSlice:
+		if ref.IsBytes() {
+			return "string"
+		}
+		elem := r.typeRef(ref.Elem)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case code.RefMap:
+		return fmt.Sprintf("Record<string, %s>", r.typeRef(ref.Elem))
+	case code.RefIdent:
+		return r.ident(ref)
+	default:
+		return "unknown"
+	}
+}
+
+func (r *renderer) ident(ref *code.TypeRef) string {
+	switch {
+	case ref.Interface != nil:
+		iface := ref.Interface
+		r.decls.Defer(func() { r.iface(iface) })
+		return iface.Name
+	case ref.Struct != nil:
+		st := ref.Struct
+		r.decls.Defer(func() {
+			if r.decls.Declare(st.Pkg, st.Name) {
+				r.object(st)
+			}
+		})
+		return st.Name
+	case ref.Pkg == "time" && ref.Name == "Time":
+		return "string"
+	case ref.Pkg == "time" && ref.Name == "Duration":
+		return "number"
+	}
+
+	// named basic types are described by their underlying types
+	switch ref.Basic() {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "float32", "float64",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+// property quotes property names that are not valid identifiers.
+func property(name string) string {
+	for i, c := range name {
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return fmt.Sprintf("%q", name)
+	}
+	return name
+}